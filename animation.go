@@ -13,51 +13,82 @@ const gifPeriods = 5
 
 type (
 	GBuilder struct {
+		Frames []Framer
+
+		// Deprecated: Images are drawn before the Frames; use
+		// Add, which draws Framers at the essay's pixel ratio.
 		Images []EncodedImage
+		// Deprecated: Bounds is extended by the bounds of the
+		// frames.
 		Bounds image.Rectangle
 	}
+
+	// Framer draws one frame of an animation when it renders,
+	// in the theme and at the pixel ratio of the essay, as a
+	// num.Builder does.  An EncodedImage is a frame drawn in
+	// advance.
+	Framer interface {
+		Frame(Builtin) EncodedImage
+	}
 )
 
-func Animation(images ...EncodedImage) GBuilder {
+func Animation(frames ...Framer) GBuilder {
 	g := GBuilder{}
-	for _, img := range images {
-		g = g.Add(img)
+	for _, f := range frames {
+		g = g.Add(f)
 	}
 	return g
 }
 
-func (g GBuilder) Add(i EncodedImage) GBuilder {
-	g.Images = append(g.Images, i)
-	g.Bounds = g.Bounds.Union(i.Bounds)
+func (g GBuilder) Add(f Framer) GBuilder {
+	g.Frames = append(g.Frames, f)
 	return g
 }
 
 func (g GBuilder) Render(builtin Builtin) (interface{}, error) {
 	defer recovery.Here()()
+	images := append([]EncodedImage(nil), g.Images...)
+	for _, f := range g.Frames {
+		images = append(images, f.Frame(builtin))
+	}
+	bounds := g.Bounds
+	for _, img := range images {
+		bounds = bounds.Union(img.Bounds)
+	}
+
 	outGif := &gif.GIF{}
-	for _, simage := range g.Images {
-		sbounds := simage.Bounds
-		palettedImage := image.NewPaletted(sbounds, nil)
+	var pixels image.Rectangle
+	for _, simage := range images {
 		img, err := simage.Decode()
 		if err != nil {
 			panic(err)
 		}
+		// Frames may have more pixels than their displayed
+		// Bounds, for high-density displays.
+		sbounds := img.Bounds()
+		palettedImage := image.NewPaletted(sbounds, nil)
 		quantizer := gogif.MedianCutQuantizer{NumColor: 256}
 		quantizer.Quantize(palettedImage, sbounds, img, image.ZP)
 
 		outGif.Image = append(outGif.Image, palettedImage)
 		outGif.Delay = append(outGif.Delay, 1)
+		pixels = pixels.Union(sbounds)
 	}
-	outGif.Config.Width = g.Bounds.Dx()
-	outGif.Config.Height = g.Bounds.Dy()
+	outGif.Config.Width = pixels.Dx()
+	outGif.Config.Height = pixels.Dy()
 	var buf bytes.Buffer
 	if err := gif.EncodeAll(&buf, outGif); err != nil {
 		panic(err)
 	}
 	ei := EncodedImage{
 		Kind:   "gif",
-		Bounds: g.Bounds,
+		Bounds: bounds,
 		Data:   buf.Bytes(),
 	}
+	if len(images) != 0 {
+		// The frames share a description.
+		ei.Alt = images[0].Alt
+		ei.Description = images[0].Description
+	}
 	return builtin.RenderImage(ei)
 }
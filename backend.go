@@ -0,0 +1,90 @@
+package essay
+
+import (
+	"fmt"
+)
+
+const (
+	HTMLBackend     = "html"
+	SlidesBackend   = "slides"
+	NotebookBackend = "ipynb"
+	TextBackend     = "text"
+	MarkdownBackend = "markdown"
+	LaTeXBackend    = "latex"
+	JSONBackend     = "json"
+
+	// LintBackend checks the essay instead of writing it,
+	// reporting problems on standard output.
+	LintBackend = "lint"
+)
+
+type (
+	// backend describes one output format of an essay.
+	backend struct {
+		// output is the file written by Close, or standard
+		// output if empty.
+		output string
+
+		// templates substitutes backend-specific templates
+		// for the named HTML templates.
+		templates map[string]string
+
+		// write, if set, writes the essay in place of the
+		// HTML templates.
+		write func(*Essay) error
+	}
+)
+
+var backends = map[string]backend{
+	HTMLBackend: {
+		output: "index.html",
+	},
+	SlidesBackend: {
+		output: "slides.html",
+		templates: map[string]string{
+			"essay.html":   "slides.html",
+			"section.html": "slide.html",
+			"callout.html": "slide-callout.html",
+		},
+	},
+	NotebookBackend: {
+		output: "essay.ipynb",
+		write:  writeNotebook,
+	},
+	TextBackend: {
+		write: writeTerminal,
+	},
+	MarkdownBackend: {
+		output: "essay.md",
+		write:  writeMarkdown,
+	},
+	LaTeXBackend: {
+		output: "essay.tex",
+		write:  writeLaTeX,
+	},
+	JSONBackend: {
+		output: "essay.json",
+		write:  writeJSON,
+	},
+	LintBackend: {
+		write: writeLint,
+	},
+}
+
+func lookupBackend(name string) (backend, error) {
+	if name == "" {
+		name = HTMLBackend
+	}
+	b, ok := backends[name]
+	if !ok {
+		return backend{}, fmt.Errorf("unknown backend: %q", name)
+	}
+	return b, nil
+}
+
+func (b backend) template(name string) string {
+	if alt, ok := b.templates[name]; ok {
+		return alt
+	}
+	return name
+}
@@ -0,0 +1,134 @@
+package essay
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const (
+	NoteCallout    CalloutKind = "note"
+	TipCallout     CalloutKind = "tip"
+	WarningCallout CalloutKind = "warning"
+	TodoCallout    CalloutKind = "todo"
+	SummaryCallout CalloutKind = "summary"
+
+	// SpeakerCallout holds speaker notes, shown on demand in
+	// the slides backend.
+	SpeakerCallout CalloutKind = "speaker"
+)
+
+const (
+	// TodoIgnore renders TODO callouts like any other callout.
+	TodoIgnore TodoMode = iota
+	// TodoReport logs every TODO callout with its section path
+	// and writes the list to todo.txt in the output directory.
+	TodoReport
+	// TodoFail is TodoReport, then Close fails if there were any.
+	TodoFail
+)
+
+var calloutTitles = map[CalloutKind]string{
+	NoteCallout:    "Note",
+	TipCallout:     "Tip",
+	WarningCallout: "Warning",
+	TodoCallout:    "TODO",
+	SummaryCallout: "Summary",
+	SpeakerCallout: "Speaker Notes",
+}
+
+type (
+	CalloutKind string
+
+	TodoMode int
+
+	calloutRenderer struct {
+		kind CalloutKind
+		structuredDoc
+	}
+
+	todoItem struct {
+		path string
+		text string
+	}
+)
+
+func (doc *structuredDoc) Callout(kind CalloutKind, list ...interface{}) {
+	callout := &calloutRenderer{
+		kind: kind,
+	}
+	callout.inherit(doc, 0)
+	for _, something := range list {
+		callout.add(something)
+	}
+	doc.add(callout)
+}
+
+func (c *calloutRenderer) Render(Builtin) (interface{}, error) {
+	defer recovery.Here()()
+	c.recordTodo()
+	return c.execute("callout.html", struct {
+		Kind  CalloutKind
+		Title string
+		Divs  []interface{}
+	}{Kind: c.kind, Title: c.kind.Title(), Divs: c.contents()})
+}
+
+func (c *calloutRenderer) recordTodo() {
+	if c.kind != TodoCallout {
+		return
+	}
+	c.todos = append(c.todos, todoItem{
+		path: c.sectionPath(),
+		text: summarize(c.contents()),
+	})
+}
+
+func (k CalloutKind) Title() string {
+	if title, ok := calloutTitles[k]; ok {
+		return title
+	}
+	return string(k)
+}
+
+func (e *Essay) reportTodos() error {
+	if e.config.Todo == TodoIgnore || len(e.todos) == 0 {
+		return nil
+	}
+	var report strings.Builder
+	for _, todo := range e.todos {
+		line := fmt.Sprintf("%s: %s", todo.path, todo.text)
+		log.Println("TODO", line)
+		report.WriteString(line)
+		report.WriteString("\n")
+	}
+	if e.backend.output != "" {
+		if err := writeFile(e.config.Dir, "todo.txt", []byte(report.String())); err != nil {
+			return err
+		}
+	}
+	if e.config.Todo == TodoFail {
+		return fmt.Errorf("%d TODO callouts remain", len(e.todos))
+	}
+	return nil
+}
+
+// summarize returns the text content of divs on one line, for
+// reporting.
+func summarize(divs []interface{}) string {
+	var parts []string
+	for _, div := range divs {
+		switch t := div.(type) {
+		case string:
+			parts = append(parts, strings.Join(strings.Fields(t), " "))
+		case template.HTML:
+			parts = append(parts, strings.Join(strings.Fields(string(t)), " "))
+		default:
+			parts = append(parts, fmt.Sprintf("<%s>", simplifyType(div)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
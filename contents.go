@@ -0,0 +1,137 @@
+package essay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+// skippedBody replaces the contents of sections and tabs that do not
+// pass the section filter.
+var skippedBody = []interface{}{"Skipped by the section filter."}
+
+type (
+	// tocEntry is one section in the table of contents.
+	tocEntry struct {
+		Heading string
+		ID      string
+		Depth   int
+		Skipped bool
+	}
+)
+
+// selected reports whether the section at the current path
+// passes the section filter.  Sections above the depth of the
+// filter pass when their names match, so that a matching
+// section renders inside its parents.
+func (e *Essay) selected() bool {
+	for i, name := range e.path {
+		if i >= len(e.sections) {
+			break
+		}
+		if !e.sections[i].MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// anchor adds the current section to the table of contents and
+// returns its unique element ID, derived from the section path.
+// Skipped sections stay in the table of contents, with the sections
+// inside them that are known without running their bodies.
+func (e *Essay) anchor(heading string, skipped bool) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, e.sectionPath())
+
+	if e.anchors == nil {
+		e.anchors = map[string]int{}
+	}
+	e.anchors[id]++
+	if n := e.anchors[id]; n > 1 {
+		id = fmt.Sprint(id, "-", n)
+	}
+
+	e.toc = append(e.toc, tocEntry{
+		Heading: heading,
+		ID:      id,
+		Depth:   e.Depth(),
+		Skipped: skipped,
+	})
+	return id
+}
+
+// anchorSkipped adds the sections inside a skipped section to the
+// table of contents.  Bodies given as a func(Document) or a
+// Displayer do not run, so the sections inside them are unknown.
+func (e *Essay) anchorSkipped(divs []interface{}) {
+	for _, div := range divs {
+		switch t := div.(type) {
+		case *sectionRenderer:
+			func() {
+				defer e.push(t.name).pop()
+				defer e.descend().ascend()
+				e.anchor(t.name, true)
+				e.anchorSkipped(t.contents())
+			}()
+		case *noteRenderer:
+			e.anchorSkipped(t.contents())
+		case *calloutRenderer:
+			e.anchorSkipped(t.contents())
+		case *tabsRenderer:
+			for _, tab := range t.tabs {
+				func() {
+					defer e.push(tab.name).pop()
+					e.anchorSkipped(tab.contents())
+				}()
+			}
+		case *slotRenderer:
+			<-t.done
+			e.anchorSkipped(t.contents())
+		case *displayRenderer:
+			e.anchorSkipped(t.contents())
+		}
+	}
+}
+
+// sectionWithContents writes the table of contents followed by the
+// section.  The sections are known only as they render, so the
+// section is spooled to a temporary file, keeping the essay
+// streaming.
+func (e *Essay) sectionWithContents(section interface{}) (interface{}, error) {
+	defer recovery.Here()()
+	spool, err := os.CreateTemp("", "essay-*.html")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	bw := bufio.NewWriter(spool)
+	if err := e.capture(bw, func() error {
+		_, err := e.section(section)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err := e.execute("toc.html", e.toc); err != nil {
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(e.out, spool)
+	return nil, err
+}
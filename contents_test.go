@@ -0,0 +1,28 @@
+package essay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectionFilterTabs(t *testing.T) {
+	for _, backend := range []string{HTMLBackend, MarkdownBackend} {
+		e, err := New(Config{Title: "Filter", Backend: backend, Sections: "Alpha/Inner"})
+		require.NoError(t, err)
+		e.Section("Alpha", func(doc Document) {
+			doc.Tabs(
+				Tab{Name: "One", Body: "tab one"},
+				Tab{Name: "Inner", Body: "tab inner"},
+			)
+		})
+
+		var buf strings.Builder
+		_, err = e.WriteTo(&buf)
+		require.NoError(t, err)
+		require.NotContains(t, buf.String(), "tab one", backend)
+		require.Contains(t, buf.String(), "tab inner", backend)
+		require.Contains(t, buf.String(), skippedBody[0], backend)
+	}
+}
@@ -1,16 +1,20 @@
 package essay
 
 import (
-	"bytes"
+	"bufio"
 	"encoding/base64"
+	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/jmacd/essay/internal/recovery"
 )
@@ -19,9 +23,22 @@ type (
 	Document interface {
 		Note(args ...interface{})
 		Section(name string, body interface{})
+		Collapsible(name string, collapsed bool, body interface{})
+		Tabs(tabs ...Tab)
+		Callout(kind CalloutKind, args ...interface{})
+		Param(name string, value interface{})
+		Slot() Slot
+		Seed(name string, seed uint64) uint64
 		Depth() int
 	}
 
+	// Tab is one alternative in a tab group, displayed in the
+	// slot shared by the group when its name is selected.
+	Tab struct {
+		Name string
+		Body interface{}
+	}
+
 	Displayer interface {
 		Display(Document)
 	}
@@ -33,12 +50,32 @@ type (
 	Builtin interface {
 		RenderImage(EncodedImage) (interface{}, error)
 		RenderTable(Table) (interface{}, error)
+
+		// Theme styles the images of Renderers, which
+		// rasterize at PixelRatio pixels per CSS pixel.
+		Theme() Theme
+		PixelRatio() float64
 	}
 
 	Essay struct {
-		config Config
-		depth  int
-		tmpl   *template.Template
+		config     Config
+		depth      int
+		tabGroups  int
+		images     int
+		path       []string
+		todos      []todoItem
+		provenance *Provenance
+		tmpl       *template.Template
+		backend    backend
+		theme      Theme
+		out        *countingWriter
+
+		profiles     []ProfileEntry
+		profileDepth int
+
+		sections []*regexp.Regexp
+		toc      []tocEntry
+		anchors  map[string]int
 
 		structuredDoc
 	}
@@ -46,18 +83,95 @@ type (
 	Config struct {
 		Dir   string
 		Title string
+		Todo  TodoMode
+
+		// Backend names the output format, by default
+		// HTMLBackend.
+		Backend string
+
+		// Graphics is the image protocol of the TextBackend.
+		Graphics Graphics
+
+		// Theme names the registered Theme of the page and its
+		// plots, by default LightTheme.
+		Theme string
+
+		// ProfileAppendix adds the render profile to the end
+		// of the essay.
+		ProfileAppendix bool
+
+		// Sections, if set, renders only the matching sections.
+		// Like the -run flag of go test, it is a slash-separated
+		// list of regular expressions, one for each level of
+		// section and tab names.
+		Sections string
+
+		// ExternalAssets writes images to files in the assets
+		// directory, instead of inlining them in the output.
+		ExternalAssets bool
+
+		// ImageScale multiplies the displayed size of images.
+		// Zero means one.
+		ImageScale float64
+
+		// PixelRatio multiplies the resolution of raster
+		// images, for high-density displays, keeping their
+		// displayed size.  Zero means one.
+		PixelRatio float64
+
+		// Seed, if non-zero, replaces the default seeds passed
+		// to Document.Seed with seeds derived from it and each
+		// name.
+		Seed uint64
+
+		// Lint sets the limits of the LintBackend.
+		Lint LintConfig
 	}
 
 	structuredDoc struct {
 		*Essay
+		lock sync.Mutex
 		divs []interface{}
+
+		// level is the depth of the contents, recorded when
+		// the document is placed so that goroutines filling
+		// Slots never read the rendering depth.  Zero means
+		// the depth while rendering, for documents created
+		// by Displayers.
+		level int
 	}
 
 	sectionRenderer struct {
+		name        string
+		collapsible bool
+		collapsed   bool
+		structuredDoc
+	}
+
+	tabsRenderer struct {
+		tabs []*tabRenderer
+		structuredDoc
+	}
+
+	tabRenderer struct {
 		name string
 		structuredDoc
 	}
 
+	sectionData struct {
+		Heading     string
+		ID          string
+		Depth       int
+		Collapsible bool
+		Collapsed   bool
+		Divs        []interface{}
+	}
+
+	tabData struct {
+		Name  string
+		Panel *tabRenderer
+	}
+
 	noteRenderer struct {
 		structuredDoc
 	}
@@ -70,20 +184,49 @@ type (
 	funcDisplayer struct {
 		docf func(Document)
 	}
+
+	countingWriter struct {
+		w io.Writer
+		n int64
+	}
 )
 
 func New(conf Config) (*Essay, error) {
+	backend, err := lookupBackend(conf.Backend)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := LookupTheme(conf.Theme)
+	if err != nil {
+		return nil, err
+	}
 	e := &Essay{
-		config: conf,
+		config:     conf,
+		backend:    backend,
+		theme:      theme,
+		provenance: newProvenance(conf.Title),
+	}
+	if conf.Sections != "" {
+		for _, pattern := range strings.Split(conf.Sections, "/") {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid section filter: %w", err)
+			}
+			e.sections = append(e.sections, re)
+		}
 	}
 	tmpl, err := template.New("essay").
 		Funcs(map[string]interface{}{
-			"css":     e.css,
-			"body":    e.body,
-			"section": e.section,
-			"render":  e.render,
-			"base64":  base64Encode,
-			"indexof": indexOf,
+			"css":      e.css,
+			"body":     e.body,
+			"section":  e.section,
+			"footer":   e.footer,
+			"appendix": e.appendix,
+			"contents": e.sectionWithContents,
+			"bytesize": byteSize,
+			"render":   e.render,
+			"base64":   e.base64,
+			"indexof":  indexOf,
 		}).
 		ParseGlob(templateGlobPath())
 	if err != nil {
@@ -94,25 +237,124 @@ func New(conf Config) (*Essay, error) {
 	return e, nil
 }
 
+// Depth returns the depth of the contents being rendered, or of
+// the essay's contents outside rendering.
 func (e *Essay) Depth() int {
-	return e.depth
+	return max(e.depth, 1)
+}
+
+func (d *structuredDoc) Depth() int {
+	if d.level != 0 {
+		return d.level
+	}
+	return d.Essay.Depth()
+}
+
+// inherit places d below the parent, levels deeper.
+func (d *structuredDoc) inherit(parent *structuredDoc, levels int) {
+	d.Essay = parent.Essay
+	d.level = parent.Depth() + levels
 }
 
+// Close writes the essay and its sidecar files to the configured
+// directory.  The essay is written to a temporary file that
+// replaces the output (e.g., index.html) only when rendering
+// succeeds.  Backends without an output file write to standard
+// output, and write no sidecar files.
 func (e *Essay) Close() (err error) {
+	if e.backend.output == "" {
+		bw := bufio.NewWriter(os.Stdout)
+		_, err = e.WriteTo(bw)
+		if ferr := bw.Flush(); err == nil {
+			err = ferr
+		}
+		if err != nil {
+			return err
+		}
+		return e.reportTodos()
+	}
+
 	if err = os.MkdirAll(e.config.Dir, os.ModePerm); err != nil {
 		return
 	}
 
-	data, err := e.generate()
-	if err != nil {
+	final := path.Join(e.config.Dir, e.backend.output)
+	temp := final + ".tmp"
+	defer os.Remove(temp)
+
+	if err = writeStream(temp, e); err != nil {
 		return err
 	}
 
-	if err = ioutil.WriteFile(path.Join(e.config.Dir, "index.html"), []byte(data), os.ModePerm); err != nil {
-		return
+	if err = e.writeSidecars(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp, final)
+}
+
+func (e *Essay) writeSidecars() error {
+	if err := e.reportTodos(); err != nil {
+		return err
+	}
+
+	if err := e.writeProvenance(); err != nil {
+		return err
 	}
 
-	return nil
+	return e.writeProfile()
+}
+
+// WriteTo streams the essay to w as it renders, so that only one
+// element of the document is held in memory at a time.
+func (e *Essay) WriteTo(w io.Writer) (int64, error) {
+	e.out = &countingWriter{w: w}
+	defer func() { e.out = nil }()
+
+	var err error
+	if e.backend.write != nil {
+		err = e.backend.write(e)
+	} else {
+		_, err = e.execute("essay.html", sectionData{
+			Heading: e.config.Title,
+			Divs:    e.contents(),
+			Depth:   1,
+		})
+	}
+	return e.out.n, err
+}
+
+// capture redirects output written by f to w.
+func (e *Essay) capture(w io.Writer, f func() error) error {
+	saved := e.out
+	e.out = &countingWriter{w: w}
+	defer func() { e.out = saved }()
+	return f()
+}
+
+func writeStream(name string, wt io.WriterTo) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if _, err = wt.WriteTo(bw); err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (c *countingWriter) Write(data []byte) (int, error) {
+	n, err := c.w.Write(data)
+	c.n += int64(n)
+	return n, err
+}
+
+func writeFile(dir, name string, data []byte) error {
+	return ioutil.WriteFile(path.Join(dir, name), data, os.ModePerm)
 }
 
 func (e *Essay) ascend() {
@@ -124,12 +366,17 @@ func (e *Essay) descend() *Essay {
 	return e
 }
 
-func (e *Essay) generate() (template.HTML, error) {
-	return e.execute("essay.html", struct {
-		Heading string
-		Divs    []interface{}
-		Depth   int
-	}{Heading: e.config.Title, Divs: e.divs, Depth: 1})
+func (e *Essay) push(name string) *Essay {
+	e.path = append(e.path, name)
+	return e
+}
+
+func (e *Essay) pop() {
+	e.path = e.path[:len(e.path)-1]
+}
+
+func (e *Essay) sectionPath() string {
+	return strings.Join(e.path, "/")
 }
 
 func (e *Essay) body(body []interface{}) (template.HTML, error) {
@@ -137,55 +384,95 @@ func (e *Essay) body(body []interface{}) (template.HTML, error) {
 }
 
 func (e *Essay) css(name string) (template.CSS, error) {
-	css, err := e.execute(name, e.config)
-	return template.CSS(css), err
+	_, err := e.execute(name, e.theme.cssVariables())
+	return "", err
 }
 
-func (e *Essay) section(section interface{}) (interface{}, error) {
+func (e *Essay) section(section interface{}) (out interface{}, err error) {
 	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
 	return e.execute("section.html", section)
 }
 
-func (e *Essay) execute(name string, arg interface{}) (_ template.HTML, retErr error) {
-	var buf bytes.Buffer
-
-	if err := e.Essay.tmpl.ExecuteTemplate(&buf, name, arg); err != nil {
-		return "", err
-	}
-
-	return template.HTML(buf.String()), nil
+// execute writes the named template directly to the output.
+// Template functions that call execute return an empty string,
+// since their output has already been written in place.
+func (e *Essay) execute(name string, arg interface{}) (template.HTML, error) {
+	return "", e.Essay.tmpl.ExecuteTemplate(e.out, e.backend.template(name), arg)
 }
 
 func (doc *structuredDoc) Note(list ...interface{}) {
 	note := &noteRenderer{}
-	note.Essay = doc.Essay
+	note.inherit(doc, 0)
 	for _, something := range list {
 		note.add(something)
 	}
 	doc.add(note)
 }
 
+// Section adds a section.  A body given as a func(Document) runs
+// when the section renders, so sections that Config.Sections
+// filters out cost nothing.
 func (doc *structuredDoc) Section(name string, body interface{}) {
 	section := &sectionRenderer{
 		name: name,
 	}
-	section.Essay = doc.Essay
+	section.inherit(doc, 1)
+
+	section.add(body)
+	doc.add(section)
+}
+
+func (doc *structuredDoc) Collapsible(name string, collapsed bool, body interface{}) {
+	section := &sectionRenderer{
+		name:        name,
+		collapsible: true,
+		collapsed:   collapsed,
+	}
+	section.inherit(doc, 1)
 
 	section.add(body)
 	doc.add(section)
 }
 
+func (doc *structuredDoc) Tabs(tabs ...Tab) {
+	group := &tabsRenderer{}
+	group.inherit(doc, 0)
+	for _, tab := range tabs {
+		panel := &tabRenderer{
+			name: tab.Name,
+		}
+		panel.inherit(doc, 0)
+		panel.add(tab.Body)
+		group.tabs = append(group.tabs, panel)
+	}
+	doc.add(group)
+}
+
 func (d *structuredDoc) add(c interface{}) {
+	if slot, ok := c.(*slotRenderer); ok {
+		slot.place(d)
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
 	d.divs = append(d.divs, c)
 }
 
+func (d *structuredDoc) contents() []interface{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.divs
+}
+
 func (e *Essay) render(arg interface{}) (interface{}, error) {
 	defer recovery.Here()()
 	switch t := arg.(type) {
 	case string, template.HTML:
 		return arg, nil
+	case *sectionRenderer, *noteRenderer, *calloutRenderer, *tabsRenderer, *tabRenderer, *displayRenderer, *slotRenderer, paramRenderer:
+		return t.(Renderer).Render(e)
 	case Renderer:
-		return t.Render(e)
+		return e.renderProfiled(t)
 	case Displayer:
 		return e.renderNamedDisplayer(t)
 	case func(Document):
@@ -199,17 +486,59 @@ func (n *noteRenderer) Render(Builtin) (interface{}, error) {
 	defer recovery.Here()()
 	return n.execute("note.html", struct {
 		Divs []interface{}
-	}{Divs: n.divs})
+	}{Divs: n.contents()})
+}
+
+func (e *Essay) renderProfiled(r Renderer) (out interface{}, err error) {
+	defer e.profile(rendererEntry, simplifyType(r))(&out)
+	return r.Render(e)
 }
 
-func (s *sectionRenderer) Render(Builtin) (interface{}, error) {
+func (s *sectionRenderer) Render(Builtin) (out interface{}, err error) {
 	defer recovery.Here()()
+	defer s.push(s.name).pop()
 	defer s.descend().ascend()
-	return s.execute("section.html", struct {
-		Heading string
-		Depth   int
-		Divs    []interface{}
-	}{Heading: s.name, Depth: s.depth, Divs: s.divs})
+	data := sectionData{
+		Heading:     s.name,
+		ID:          s.anchor(s.name, !s.selected()),
+		Depth:       s.Essay.Depth(),
+		Collapsible: s.collapsible,
+		Collapsed:   s.collapsed,
+	}
+	if !s.selected() {
+		s.anchorSkipped(s.contents())
+		data.Divs = skippedBody
+		return s.execute("section.html", data)
+	}
+	defer s.profile(sectionEntry, s.name)(&out)
+	data.Divs = s.contents()
+	return s.execute("section.html", data)
+}
+
+func (t *tabsRenderer) Render(Builtin) (interface{}, error) {
+	defer recovery.Here()()
+	t.tabGroups++
+	var tabs []tabData
+	for _, panel := range t.tabs {
+		tabs = append(tabs, tabData{
+			Name:  panel.name,
+			Panel: panel,
+		})
+	}
+	return t.execute("tabs.html", struct {
+		Group string
+		Tabs  []tabData
+	}{Group: fmt.Sprint("tabs-", t.tabGroups), Tabs: tabs})
+}
+
+func (t *tabRenderer) Render(Builtin) (interface{}, error) {
+	defer recovery.Here()()
+	defer t.push(t.name).pop()
+	if !t.selected() {
+		t.anchorSkipped(t.contents())
+		return t.body(skippedBody)
+	}
+	return t.body(t.contents())
 }
 
 func (d *displayRenderer) Render(Builtin) (interface{}, error) {
@@ -218,28 +547,40 @@ func (d *displayRenderer) Render(Builtin) (interface{}, error) {
 		Type  string
 		Depth int
 		Divs  []interface{}
-	}{Type: d.dtype, Depth: d.depth, Divs: d.divs})
+	}{Type: d.dtype, Depth: d.Essay.Depth(), Divs: d.contents()})
 }
 
-func (e *Essay) renderNamedDisplayer(displayer Displayer) (interface{}, error) {
+func (e *Essay) renderNamedDisplayer(displayer Displayer) (out interface{}, err error) {
 	defer recovery.Here()()
 	defer e.descend().ascend()
-	dtype := simplifyType(displayer)
-	if stringer, ok := displayer.(fmt.Stringer); ok {
-		dtype = dtype + ": " + stringer.String()
-	}
+	dtype := displayerType(displayer)
+	defer e.profile(displayerEntry, dtype)(&out)
 
 	return e.renderDisplayer(dtype, displayer)
 }
 
 func (e *Essay) renderDisplayer(dtype string, displayer Displayer) (interface{}, error) {
 	defer recovery.Here()()
+	return e.display(dtype, displayer).Render(e)
+}
+
+// display expands a Displayer into a document.
+func (e *Essay) display(dtype string, displayer Displayer) *displayRenderer {
 	dd := &displayRenderer{
 		dtype: dtype,
 	}
 	dd.Essay = e
 	displayer.Display(dd)
-	return dd.Render(e)
+	return dd
+}
+
+// displayerType names a Displayer for display.
+func displayerType(displayer Displayer) string {
+	dtype := simplifyType(displayer)
+	if stringer, ok := displayer.(fmt.Stringer); ok {
+		dtype = dtype + ": " + stringer.String()
+	}
+	return dtype
 }
 
 func simplifyType(d interface{}) string {
@@ -254,8 +595,12 @@ func simplifyType(d interface{}) string {
 	return s
 }
 
-func base64Encode(in []byte) template.HTML {
-	return template.HTML(base64.StdEncoding.EncodeToString(in))
+func (e *Essay) base64(in []byte) (template.HTML, error) {
+	enc := base64.NewEncoder(base64.StdEncoding, e.out)
+	if _, err := enc.Write(in); err != nil {
+		return "", err
+	}
+	return "", enc.Close()
 }
 
 func indexOf(slice interface{}, idx int) interface{} {
@@ -266,10 +611,52 @@ func indexOf(slice interface{}, idx int) interface{} {
 	return sval.Index(idx).Interface()
 }
 
+var todoModes = map[string]TodoMode{
+	"ignore": TodoIgnore,
+	"report": TodoReport,
+	"fail":   TodoFail,
+}
+
+// Main writes an essay configured by the standard command-line
+// flags.  By default the output directory is derived from the
+// title.
 func Main(title string, writer func(Document)) {
+	var (
+		dir      = flag.String("dir", strings.Replace(strings.ToLower(title), " ", "_", -1), "output directory")
+		backend  = flag.String("backend", HTMLBackend, "output format: html, slides, ipynb, markdown, latex, json, text or lint")
+		assets   = flag.String("assets", "inline", "images inline in a single file, or external in the assets directory")
+		scale    = flag.Float64("scale", 1, "image display scale factor")
+		ratio    = flag.Float64("pixel-ratio", 1, "raster image resolution multiplier, e.g. 2 for high-density displays")
+		sections = flag.String("section", "", "render only sections matching this slash-separated list of patterns")
+		seed     = flag.Uint64("seed", 0, "override the essay's random seeds, if non-zero")
+		todo     = flag.String("todo", "ignore", "TODO callouts: ignore, report or fail")
+		profile  = flag.Bool("profile", false, "append the render profile to the essay")
+		graphics = flag.String("graphics", "", "terminal images for the text backend: kitty, sixel or none")
+		theme    = flag.String("theme", LightTheme, "page and plot theme: light, dark, print or a registered theme")
+	)
+	flag.Parse()
+
+	if *assets != "inline" && *assets != "external" {
+		log.Fatalf("unknown assets: %q", *assets)
+	}
+	todoMode, ok := todoModes[*todo]
+	if !ok {
+		log.Fatalf("unknown todo mode: %q", *todo)
+	}
+
 	ess, err := New(Config{
-		Title: title,
-		Dir:   strings.Replace(strings.ToLower(title), " ", "_", -1),
+		Title:           title,
+		Dir:             *dir,
+		Backend:         *backend,
+		Todo:            todoMode,
+		ProfileAppendix: *profile,
+		Graphics:        Graphics(*graphics),
+		Theme:           *theme,
+		Sections:        *sections,
+		ExternalAssets:  *assets == "external",
+		ImageScale:      *scale,
+		PixelRatio:      *ratio,
+		Seed:            *seed,
 	})
 	if err != nil {
 		log.Fatal(err)
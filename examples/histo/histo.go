@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"sort"
 
 	"github.com/jmacd/essay"
 	"github.com/jmacd/essay/lib/gonum/loghist"
@@ -481,6 +482,20 @@ func setLogScale(p *plot.Plot, ax axis, dist HeavyDist, factor float64, data XY1
 	}
 }
 
+// ruleCount applies a loghist bin rule to unweighted data.
+func ruleCount(rule loghist.BinRule) func([]float64) float64 {
+	return func(x []float64) float64 {
+		sample := make([]loghist.WeightedValue, len(x))
+		for i, v := range x {
+			sample[i] = loghist.WeightedValue{Value: v, Weight: 1}
+		}
+		sort.Slice(sample, func(i, j int) bool {
+			return sample[i].Value < sample[j].Value
+		})
+		return float64(rule(sample))
+	}
+}
+
 func showHistSizes(doc essay.Document, dist Dist) essay.Table {
 	// Note https://en.wikipedia.org/wiki/Histogram#Number_of_bins_and_width
 
@@ -505,24 +520,13 @@ func showHistSizes(doc essay.Document, dist Dist) essay.Table {
 				return math.Sqrt(float64(len(x)))
 			},
 		},
-		{
-			"Rice ~x^1/3",
-			func(x []float64) float64 {
-				y := math.Pow(float64(len(x)), 1.0/3.0)
-				return math.Ceil(2 * y)
-			},
-		},
-		{
-			"Sturges ~log2(x)",
-			func(x []float64) float64 {
-				return math.Ceil(math.Log2(float64(len(x)))) + 1
-			},
-		},
+		{"Rice ~x^1/3", ruleCount(loghist.Rice)},
+		{"Sturges ~log2(x)", ruleCount(loghist.Sturges)},
+		{"Freedman–Diaconis ~IQR", ruleCount(loghist.FreedmanDiaconis)},
 	}
-	// Note: there are also a number of rules better suited for
-	// skewed distributions: "Doane", "Scott", "Freedman–Diaconis"
-	// rules, then a couple of size-choice algorithms based on an
-	// optimization: "Min-Squared-Error", "Shimazaki and Shinomoto".
+	// Note: loghist also has the "Doane", "Scott" and
+	// "Shimazaki–Shinomoto" rules, the last based on an
+	// optimization.
 
 	// Note: gonum/plot default is the square root of the sum of
 	// the Y values.
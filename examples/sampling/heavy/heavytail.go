@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"runtime"
 
 	"github.com/jmacd/essay"
 	ms "github.com/jmacd/essay/examples/internal/multishape"
@@ -47,16 +48,26 @@ func main() {
 }
 
 func heavytailSampling(doc essay.Document) {
+	// Each experiment builds its own population, so bound how
+	// many are in memory at once.
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
 	for _, cratio := range colorCountRatios {
 		cratio := cratio
 		for _, sratio := range sampleSizeRatios {
 			sratio := sratio
 
+			// Each experiment is independent, compute them
+			// concurrently.
+			slot := doc.Slot()
 			doc.Section(
 				fmt.Sprintf("Heavy 1D Colors @%.2f%% Sample @%.2f%%", cratio*100, sratio*100),
-				func(doc essay.Document) {
-					heavySample(cratio, sratio, doc)
-				})
+				slot)
+			go func() {
+				defer slot.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				heavySample(cratio, sratio, slot)
+			}()
 		}
 	}
 }
@@ -65,7 +76,10 @@ func heavySample(cratio, sratio float64, doc essay.Document) {
 	model := models.NewModel2(plot.ColorVar)
 
 	// Build synthetic data
-	u := universe.New("heavy-1d", 1723)
+	u := universe.New("heavy-1d", doc.Seed("heavy-1d", 1723))
+
+	doc.Param("color ratio", cratio)
+	doc.Param("sample ratio", sratio)
 
 	pointsPerPeriod := float64(duration*rate) / adaptivePeriods
 	sampleSize := int(sratio * pointsPerPeriod)
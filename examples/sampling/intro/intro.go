@@ -78,7 +78,7 @@ func main() {
 
 func intro(doc essay.Document) {
 
-	doc.Note(`TL;DR Honestly not for reading. Start with "Data
+	doc.Callout(essay.SummaryCallout, `Honestly not for reading. Start with "Data
 	Sampling", below.`)
 
 	doc.Note(`The terms "sampling" and "sample" have many formal
@@ -216,7 +216,7 @@ func computer(doc essay.Document) {
 	represents a latency value (Y), and we expect to see samples
 	where they lie on the Y axis.`)
 
-	doc.Note(`TODO: Note: the details of [f] and [g] are
+	doc.Callout(essay.TodoCallout, `Note: the details of [f] and [g] are
 	irrelevant as long as we're displaying the full data. As we
 	begin to downsample to fewer points and save less data, we may
 	adopt a strategy to maintain uniform coverage in [f] or [g],
@@ -224,7 +224,7 @@ func computer(doc essay.Document) {
 	more samples when there is higher frequency, in [g] we prefer
 	to keep more samples when there is higher spread of latencies.`)
 
-	doc.Note(`TODO: Note: [f] corresponds to [a] in the sense that
+	doc.Callout(essay.TodoCallout, `Note: [f] corresponds to [a] in the sense that
         the stack of [f] samples rises to the [a] line on a plot with
         uniform sample density (regardless of downsampling).  [g]
         corresponds to [b] in the sense that the sample density rises
@@ -234,15 +234,15 @@ func computer(doc essay.Document) {
 	and timeseries which gives us another way to visualize this data: 
 	variable-width bar graphs.`)
 
-	doc.Note(`TODO: Outline: 1. Show that a single "bag of spans"
+	doc.Callout(essay.TodoCallout, `Outline: 1. Show that a single "bag of spans"
 	sample can generate [f] or [g] which can compute approximate
 	[a], [b], [c], [d], and [e].  2. Show that we can downsample
 	one continuous variable, can we estimate the errors?`)
 
-	doc.Note(`TODO: Outline: Categorical variables. Repeat steps 1
+	doc.Callout(essay.TodoCallout, `Outline: Categorical variables. Repeat steps 1
 	and 2 above.`)
 
-	doc.Note(`TODO: Outline: Multiple variables. Repeat steps 1
+	doc.Callout(essay.TodoCallout, `Outline: Multiple variables. Repeat steps 1
 	and 2 above.`)
 
 	doc.Section(`Small`, sampleSmall)
@@ -314,7 +314,7 @@ func sampleExample(doc essay.Document) {
 		duration = 10.0
 	)
 
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	latencyVar := ms.Variable("latency")
 
 	rander := u.PositiveNormal(10, 1)
@@ -435,7 +435,7 @@ func sampleExample(doc essay.Document) {
 }
 
 func sampleHistogram(doc essay.Document) {
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	showSampleHistogram(doc, u, u.Exponential(1))
 	showSampleHistogram(doc, u, u.PositiveNormal(1, 1))
 }
@@ -488,7 +488,7 @@ func showSampleHistogram(doc essay.Document, u *universe.Universe, rander univer
 }
 
 func sampleTimeseriesRate(doc essay.Document) {
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	showSampleTimeseriesRate(doc, u, u.Exponential(1))
 	showSampleTimeseriesRate(doc, u, u.PositiveNormal(1, 1))
 	showSampleTimeseriesRate(doc, u, u.PositiveLogNormal(1, 1))
@@ -639,7 +639,7 @@ func sampleLatency(points ms.Population, size int, scale sampler.Scale) ms.Popul
 }
 
 func sampleTimeseriesLatency(doc essay.Document) {
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	showSampleTimeseriesLatency(doc, u, u.Exponential(1), false)
 	showSampleTimeseriesLatency(doc, u, u.PositiveNormal(1, 1), false)
 	showSampleTimeseriesLatency(doc, u, u.PositiveLogNormal(1, 1), true)
@@ -733,7 +733,7 @@ func radiusFor(min, max, value float64) vg.Length {
 }
 
 func sampleTimeseriesAdaptive(doc essay.Document) {
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	showSampleTimeseriesAdaptive(doc, u, u.Exponential(1), false)
 	showSampleTimeseriesAdaptive(doc, u, u.PositiveNormal(1, 1), false)
 	showSampleTimeseriesAdaptive(doc, u, u.PositiveLogNormal(1, 1), true)
@@ -802,7 +802,7 @@ func showSampleTimeseriesAdaptive(doc essay.Document, u *universe.Universe, rand
 }
 
 func sampleTimeseriesAnimated(doc essay.Document) {
-	u := universe.New("testing", 123)
+	u := universe.New("testing", doc.Seed("testing", 123))
 	showSampleTimeseriesAnimated(doc, u, u.Exponential(1), false)
 	showSampleTimeseriesAnimated(doc, u, u.PositiveNormal(1, 1), false)
 	showSampleTimeseriesAnimated(doc, u, u.PositiveLogNormal(1, 1), true)
@@ -818,7 +818,7 @@ func showSampleTimeseriesAnimated(doc essay.Document, u *universe.Universe, rand
 
 	basic := universe.NewContinuousTimeseries(u, latencyVar, rander, rate, duration)
 	points := universe.MakePopulation(basic)
-	var plots []essay.EncodedImage
+	var plots []essay.Framer
 
 	ratios := []float64{float64(len(points)-1) / float64(len(points)), 0.9, 0.8, 0.7, 0.6, 0.5, 0.4, .3, .2, .1, .09, .08, .07, .06, .05, .04, .03, .02, .01}
 
@@ -837,8 +837,7 @@ func showSampleTimeseriesAnimated(doc essay.Document, u *universe.Universe, rand
 			Y(num.Axis().Min(0).LogScale(logscale)).
 			Legend().
 			Add(latencyFreqPoints(spoints)).
-			Add(latencyQuantiles(displayQuantiles, duration/periods, spoints)...).
-			Image(essay.PNG))
+			Add(latencyQuantiles(displayQuantiles, duration/periods, spoints)...))
 	}
 
 	for _, p := range plots {
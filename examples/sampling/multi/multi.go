@@ -50,19 +50,26 @@ func main() {
 }
 
 func multiSampling(doc essay.Document) {
+	var tabs []essay.Tab
 	for _, ratio := range sampleSizeRatios {
 		ratio := ratio
-		doc.Section(fmt.Sprintf("2D Sample @%.2f%%", ratio*100), func(doc essay.Document) {
-			twoDimensions(ratio, doc)
+		tabs = append(tabs, essay.Tab{
+			Name: fmt.Sprintf("2D Sample @%.2f%%", ratio*100),
+			Body: func(doc essay.Document) {
+				twoDimensions(ratio, doc)
+			},
 		})
 	}
+	doc.Tabs(tabs...)
 }
 
 func twoDimensions(sampleSizeRatio float64, doc essay.Document) {
 	model := models.NewModel1(plot.LatencyVar, plot.ColorVar, lquality)
 
 	// Build synthetic data
-	u := universe.New("test-2d", 499)
+	u := universe.New("test-2d", doc.Seed("test-2d", 499))
+
+	doc.Param("sample size ratio", sampleSizeRatio)
 
 	randerL := u.PositiveNormal(10, 10)
 	randerC := u.Normal(0, 5)
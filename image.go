@@ -2,9 +2,13 @@ package essay
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"image"
 	"image/png"
+	"os"
+	"path"
 
 	"github.com/jmacd/essay/internal/recovery"
 )
@@ -12,6 +16,10 @@ import (
 const (
 	PNG ImageKind = "png"
 	SVG ImageKind = "svg"
+
+	// assetDir holds the images of an essay written with
+	// Config.ExternalAssets.
+	assetDir = "assets"
 )
 
 type (
@@ -21,12 +29,80 @@ type (
 		Kind   ImageKind
 		Bounds image.Rectangle
 		Data   []byte
+
+		// Alt is the text alternative for readers who
+		// cannot see the image, Caption is displayed below it.
+		Alt     string
+		Caption string
+
+		// Description is a long description, for images
+		// that alt text cannot summarize.
+		Description string
+
+		// Fallback lists the data shown in the image, for
+		// screen readers.  It is hidden from view.
+		Fallback *Table
+	}
+
+	imageData struct {
+		EncodedImage
+		ID     string
+		Width  int
+		Height int
+
+		// Src is the path of an external asset, otherwise
+		// the image is inlined.
+		Src string
 	}
 )
 
-func (e Essay) RenderImage(img EncodedImage) (interface{}, error) {
+func (e *Essay) RenderImage(img EncodedImage) (interface{}, error) {
 	defer recovery.Here()()
-	return e.execute("image.html", img)
+	data := imageData{EncodedImage: img}
+	if img.Description != "" {
+		e.images++
+		data.ID = fmt.Sprint("image-", e.images)
+	}
+	data.Width, data.Height = e.displaySize(img.Bounds)
+	if e.config.ExternalAssets {
+		src, err := e.asset(img)
+		if err != nil {
+			return nil, err
+		}
+		data.Src = src
+	}
+	return e.execute("image.html", data)
+}
+
+// Frame returns the image, as an animation frame drawn in advance.
+func (img EncodedImage) Frame(Builtin) EncodedImage {
+	return img
+}
+
+// PixelRatio returns the raster image resolution of the essay.
+func (e *Essay) PixelRatio() float64 {
+	return e.config.PixelRatio
+}
+
+// displaySize is the size of an image as displayed, after
+// Config.ImageScale.
+func (e *Essay) displaySize(bounds image.Rectangle) (int, int) {
+	scale := e.config.ImageScale
+	if scale == 0 {
+		scale = 1
+	}
+	return int(float64(bounds.Dx())*scale + 0.5), int(float64(bounds.Dy())*scale + 0.5)
+}
+
+// asset writes an image to the assets directory, named by its
+// content, and returns its path relative to the essay.
+func (e *Essay) asset(img EncodedImage) (string, error) {
+	if err := os.MkdirAll(path.Join(e.config.Dir, assetDir), os.ModePerm); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(img.Data)
+	name := path.Join(assetDir, hex.EncodeToString(sum[:8])+"."+string(img.Kind))
+	return name, writeFile(e.config.Dir, name, img.Data)
 }
 
 func Image(i image.Image) EncodedImage {
@@ -2,16 +2,25 @@ package recovery
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 )
 
+// trace enables ENTER/EXIT tracing.  Recovered panics are always
+// reported.
+var trace = os.Getenv("ESSAY_TRACE") != ""
+
 func Here() func() {
 	_, file, line, _ := runtime.Caller(1)
-	fmt.Println("ENTER", file, ":", line)
+	if trace {
+		fmt.Fprintln(os.Stderr, "ENTER", file, ":", line)
+	}
 	return func() {
-		fmt.Println("EXIT", file, ":", line)
+		if trace {
+			fmt.Fprintln(os.Stderr, "EXIT", file, ":", line)
+		}
 		if ret := recover(); ret != nil {
-			fmt.Println("RECOVERED", ret)
+			fmt.Fprintln(os.Stderr, "RECOVERED", ret)
 		}
 	}
 }
@@ -0,0 +1,239 @@
+package essay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+type (
+	// jsonDoc writes the essay as a JSON document tree, for
+	// other tools to consume.  Each node is an object with a
+	// "kind"; nodes with contents list them in "body".
+	jsonDoc struct {
+		essay *Essay
+
+		// first records, for each open array, whether the
+		// next element is its first.
+		first []bool
+	}
+)
+
+func writeJSON(e *Essay) error {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	j := &jsonDoc{essay: e}
+	if err := j.write(`{"title":`, jsonString(e.config.Title), `,"body":[`); err != nil {
+		return err
+	}
+	j.first = append(j.first, true)
+	if err := e.walk(j, e.contents()); err != nil {
+		return err
+	}
+	j.first = j.first[:len(j.first)-1]
+
+	prov, err := json.Marshal(e.provenance)
+	if err != nil {
+		return err
+	}
+	return j.write("\n],\"provenance\":", string(prov), "}\n")
+}
+
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		panic(err)
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func (j *jsonDoc) write(parts ...string) error {
+	for _, part := range parts {
+		if _, err := io.WriteString(j.essay.out, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// elem begins the next element of the innermost array.
+func (j *jsonDoc) elem() error {
+	top := len(j.first) - 1
+	if j.first[top] {
+		j.first[top] = false
+		return j.write("\n")
+	}
+	return j.write(",\n")
+}
+
+// open begins an array of nodes.
+func (j *jsonDoc) open(prefix string) error {
+	j.first = append(j.first, true)
+	return j.write(prefix, "[")
+}
+
+func (j *jsonDoc) close(suffix string) error {
+	j.first = j.first[:len(j.first)-1]
+	return j.write("]", suffix)
+}
+
+func (j *jsonDoc) enter(kind nodeKind, name string) error {
+	if err := j.elem(); err != nil {
+		return err
+	}
+	if err := j.write(`{"kind":`, jsonString(kind.String())); err != nil {
+		return err
+	}
+	if name != "" {
+		if err := j.write(`,"name":`, jsonString(name)); err != nil {
+			return err
+		}
+	}
+	if kind == sectionNode {
+		if err := j.write(fmt.Sprintf(`,"depth":%d`, j.essay.Depth())); err != nil {
+			return err
+		}
+	}
+	return j.open(`,"body":`)
+}
+
+func (j *jsonDoc) leave(nodeKind) error {
+	return j.close("}")
+}
+
+func (j *jsonDoc) text(s string) error {
+	if err := j.elem(); err != nil {
+		return err
+	}
+	return j.write(`{"kind":"text","text":`, jsonString(flowText(s)), "}")
+}
+
+func (j *jsonDoc) html(h template.HTML) error {
+	if err := j.elem(); err != nil {
+		return err
+	}
+	return j.write(`{"kind":"html","html":`, jsonString(string(h)), "}")
+}
+
+func (j *jsonDoc) Theme() Theme {
+	return j.essay.theme
+}
+
+func (j *jsonDoc) PixelRatio() float64 {
+	return j.essay.PixelRatio()
+}
+
+func (j *jsonDoc) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	if err := j.elem(); err != nil {
+		return nil, err
+	}
+	width, height := j.essay.displaySize(img.Bounds)
+	if err := j.write(fmt.Sprintf(`{"kind":"image","format":%s,"width":%d,"height":%d,`,
+		jsonString(string(img.Kind)), width, height)); err != nil {
+		return nil, err
+	}
+	if img.Alt != "" {
+		if err := j.write(`"alt":`, jsonString(img.Alt), ","); err != nil {
+			return nil, err
+		}
+	}
+	if img.Caption != "" {
+		if err := j.write(`"caption":`, jsonString(img.Caption), ","); err != nil {
+			return nil, err
+		}
+	}
+	if img.Description != "" {
+		if err := j.write(`"description":`, jsonString(img.Description), ","); err != nil {
+			return nil, err
+		}
+	}
+	if j.essay.config.ExternalAssets {
+		src, err := j.essay.asset(img)
+		if err != nil {
+			return nil, err
+		}
+		return nil, j.write(`"src":`, jsonString(src), "}")
+	}
+	if err := j.write(`"data":"`); err != nil {
+		return nil, err
+	}
+	if _, err := j.essay.base64(img.Data); err != nil {
+		return nil, err
+	}
+	return nil, j.write(`"}`)
+}
+
+// RenderTable writes the top row, left column and cells of a
+// table, each cell as an array of nodes.
+func (j *jsonDoc) RenderTable(t Table) (interface{}, error) {
+	defer recovery.Here()()
+	cells := func(items []interface{}) error {
+		for _, item := range items {
+			if err := j.elem(); err != nil {
+				return err
+			}
+			if err := j.open(""); err != nil {
+				return err
+			}
+			if err := j.essay.visit(j, item); err != nil {
+				return err
+			}
+			if err := j.close(""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	list := func(key string, items []interface{}) error {
+		if items == nil {
+			return nil
+		}
+		if err := j.open(`,"` + key + `":`); err != nil {
+			return err
+		}
+		if err := cells(items); err != nil {
+			return err
+		}
+		return j.close("")
+	}
+
+	if err := j.elem(); err != nil {
+		return nil, err
+	}
+	if err := j.write(`{"kind":"table"`); err != nil {
+		return nil, err
+	}
+	if err := list("top", t.TopRow); err != nil {
+		return nil, err
+	}
+	if err := list("left", t.LeftCol); err != nil {
+		return nil, err
+	}
+	if err := j.open(`,"cells":`); err != nil {
+		return nil, err
+	}
+	for _, row := range t.Cells {
+		if err := j.elem(); err != nil {
+			return nil, err
+		}
+		if err := j.open(""); err != nil {
+			return nil, err
+		}
+		if err := cells(row); err != nil {
+			return nil, err
+		}
+		if err := j.close(""); err != nil {
+			return nil, err
+		}
+	}
+	return nil, j.close("}")
+}
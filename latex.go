@@ -0,0 +1,276 @@
+package essay
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"image/gif"
+	"io"
+	"strings"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+// pointsPerPixel converts CSS pixels to TeX points.
+const pointsPerPixel = 0.75
+
+var (
+	latexSections = []string{
+		"section",
+		"subsection",
+		"subsubsection",
+		"paragraph",
+		"subparagraph",
+	}
+
+	latexEscaper = strings.NewReplacer(
+		`\`, `\textbackslash{}`,
+		`{`, `\{`,
+		`}`, `\}`,
+		`$`, `\$`,
+		`&`, `\&`,
+		`#`, `\#`,
+		`^`, `\^{}`,
+		`_`, `\_`,
+		`~`, `\textasciitilde{}`,
+		`%`, `\%`,
+		`<`, `\textless{}`,
+		`>`, `\textgreater{}`,
+		`|`, `\textbar{}`,
+	)
+)
+
+type (
+	// latex writes the essay as a LaTeX article.  Images are
+	// always written as external assets.
+	latex struct {
+		essay *Essay
+
+		// inline is set in table cells, where paragraphs and
+		// centered figures are not allowed.
+		inline bool
+
+		// svg is set once an SVG image is included, which
+		// needs the svg package.
+		svg bool
+	}
+)
+
+func writeLaTeX(e *Essay) error {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	// The body is buffered, since the preamble depends on
+	// its images.  Images are external, so the body is small.
+	tex := &latex{essay: e}
+	var body bytes.Buffer
+	if err := e.capture(&body, func() error {
+		if err := e.walkNode(tex, sectionNode, e.config.Title, e.contents()); err != nil {
+			return err
+		}
+		return tex.footer()
+	}); err != nil {
+		return err
+	}
+
+	packages := `\usepackage{graphicx}`
+	if tex.svg {
+		// The svg package runs inkscape, with -shell-escape.
+		packages += "\n" + `\usepackage{svg}`
+	}
+	if _, err := fmt.Fprintf(e.out, `\documentclass{article}
+\usepackage[utf8]{inputenc}
+%s
+\title{%s}
+\date{%s}
+\begin{document}
+\maketitle
+
+`, packages, latexEscape(e.config.Title), e.provenance.Timestamp.Format("January 2, 2006")); err != nil {
+		return err
+	}
+	if _, err := body.WriteTo(e.out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.out, "\\end{document}\n")
+	return err
+}
+
+func latexEscape(s string) string {
+	return latexEscaper.Replace(s)
+}
+
+func (tex *latex) enter(kind nodeKind, name string) error {
+	switch kind {
+	case sectionNode:
+		// The essay title is the \maketitle.
+		depth := tex.essay.Depth()
+		if depth == 1 {
+			return nil
+		}
+		command := latexSections[min(depth-2, len(latexSections)-1)]
+		return tex.paragraph(`\` + command + `{` + latexEscape(name) + `}`)
+	case calloutNode:
+		return tex.paragraph(`\begin{quote}` + "\n" + `\textbf{` + latexEscape(CalloutKind(name).Title()) + `}`)
+	case tabNode:
+		return tex.paragraph(`\textbf{` + latexEscape(name) + `}`)
+	case displayNode:
+		if name != "" {
+			return tex.paragraph(`\textbf{` + latexEscape("<"+name+">") + `}`)
+		}
+	}
+	return nil
+}
+
+func (tex *latex) leave(kind nodeKind) error {
+	if kind == calloutNode {
+		return tex.paragraph(`\end{quote}`)
+	}
+	return nil
+}
+
+func (tex *latex) text(s string) error {
+	return tex.paragraph(latexEscape(flowText(s)))
+}
+
+// html writes the text of HTML, without its markup.
+func (tex *latex) html(h template.HTML) error {
+	return tex.text(html.UnescapeString(htmlTag.ReplaceAllString(string(h), " ")))
+}
+
+func (tex *latex) Theme() Theme {
+	return tex.essay.theme
+}
+
+func (tex *latex) PixelRatio() float64 {
+	return tex.essay.PixelRatio()
+}
+
+func (tex *latex) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	if img.Kind == "gif" {
+		// LaTeX does not read GIF; use the first frame.
+		frame, err := gif.Decode(bytes.NewReader(img.Data))
+		if err != nil {
+			return nil, err
+		}
+		img = Image(frame)
+	}
+	src, err := tex.essay.asset(img)
+	if err != nil {
+		return nil, err
+	}
+	width, _ := tex.essay.displaySize(img.Bounds)
+	include := "includegraphics"
+	if img.Kind == SVG {
+		include = "includesvg"
+		tex.svg = true
+	}
+	graphic := fmt.Sprintf(`\%s[width=%.1fpt]{%s}`, include, float64(width)*pointsPerPixel, src)
+	if tex.inline {
+		return nil, tex.paragraph(graphic)
+	}
+	return nil, tex.paragraph(`\begin{center}` + "\n" + graphic + "\n" + `\end{center}`)
+}
+
+func (tex *latex) RenderTable(t Table) (interface{}, error) {
+	defer recovery.Here()()
+	cell := func(item interface{}) (string, error) {
+		var buf bytes.Buffer
+		inner := &latex{essay: tex.essay, inline: true}
+		if err := tex.essay.capture(&buf, func() error {
+			return tex.essay.visit(inner, item)
+		}); err != nil {
+			return "", err
+		}
+		tex.svg = tex.svg || inner.svg
+		return strings.Join(strings.Fields(buf.String()), " "), nil
+	}
+	row := func(left interface{}, items []interface{}) (string, error) {
+		var cells []string
+		if t.LeftCol != nil {
+			c, err := cell(left)
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, c)
+		}
+		for _, item := range items {
+			c, err := cell(item)
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, c)
+		}
+		return strings.Join(cells, " & ") + ` \\`, nil
+	}
+
+	var columns int
+	for _, items := range t.Cells {
+		columns = max(columns, len(items))
+	}
+	if t.LeftCol != nil {
+		columns++
+	}
+
+	lines := []string{`\begin{center}`, `\begin{tabular}{` + strings.Repeat("c", columns) + `}`}
+	if t.TopRow != nil {
+		line, err := row("", t.TopRow)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line, `\hline`)
+	}
+	for i, items := range t.Cells {
+		var left interface{}
+		if i < len(t.LeftCol) {
+			left = t.LeftCol[i]
+		}
+		line, err := row(left, items)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, `\end{tabular}`, `\end{center}`)
+	return nil, tex.paragraph(strings.Join(lines, "\n"))
+}
+
+func (tex *latex) footer() error {
+	p := tex.essay.provenance
+	line := fmt.Sprintf("Built %s with %s", p.Timestamp.Format("2006-01-02 15:04:05 MST"), p.GoVersion)
+	if p.Program != "" {
+		line += " from " + p.Program
+	}
+	if p.Revision != "" {
+		line += " at revision " + p.Revision
+	}
+	lines := []string{`\vfill`, `\hrule`, `\medskip`, `{\small ` + latexEscape(line) + `}`}
+	if len(p.Params) != 0 {
+		lines = append(lines, `\begin{itemize}\small`)
+		for _, param := range p.Params {
+			name := param.Name
+			if param.Section != "" {
+				name = param.Section + ": " + name
+			}
+			lines = append(lines, `\item `+latexEscape(name)+`: \texttt{`+latexEscape(fmt.Sprint(param.Value))+`}`)
+		}
+		lines = append(lines, `\end{itemize}`)
+	}
+	return tex.paragraph(strings.Join(lines, "\n"))
+}
+
+// paragraph writes one block, or a phrase in table cells.
+func (tex *latex) paragraph(s string) error {
+	if s == "" {
+		return nil
+	}
+	sep := "\n\n"
+	if tex.inline {
+		sep = " "
+	}
+	_, err := io.WriteString(tex.essay.out, s+sep)
+	return err
+}
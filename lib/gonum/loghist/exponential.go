@@ -0,0 +1,248 @@
+package loghist
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+const (
+	// The range of scales in the OpenTelemetry data model.
+	MinExponentialScale = -10
+	MaxExponentialScale = 20
+
+	// DefaultExponentialSize is the default maximum number of
+	// buckets of the OpenTelemetry SDK.
+	DefaultExponentialSize = 160
+)
+
+type (
+	// ExponentialBinner produces the base-2 exponential buckets
+	// of OpenTelemetry.  At scale s the base is 2^(2^-s), and
+	// bucket i holds the values in (base^i, base^(i+1)].  Starting
+	// from MaxScale, the scale is reduced until the positive and
+	// the negative values each fit in MaxSize buckets.  Values
+	// within ZeroThreshold of zero are counted in a zero bucket,
+	// and negative values in buckets mirroring the positive.
+	//
+	// The buckets are fixed by the scale, so BinPoints ignores
+	// its DataTransformer.
+	ExponentialBinner struct {
+		MaxScale      int32
+		MaxSize       int
+		ZeroThreshold float64
+	}
+
+	// ExponentialHistogram is the OTLP exponential histogram
+	// data model.
+	ExponentialHistogram struct {
+		Scale         int32
+		ZeroThreshold float64
+		ZeroCount     uint64
+		Positive      ExponentialBuckets
+		Negative      ExponentialBuckets
+		Count         uint64
+		Sum           float64
+	}
+
+	// ExponentialBuckets counts the values of one sign, where
+	// BucketCounts[j] is the count of bucket Offset+j.
+	ExponentialBuckets struct {
+		Offset       int32
+		BucketCounts []uint64
+	}
+
+	// exponential is the binned data, with weights.
+	exponential struct {
+		scale              int32
+		zeroThreshold      float64
+		zero               float64
+		positive, negative expBuckets
+		sum                float64
+	}
+
+	expBuckets struct {
+		offset int32
+		counts []float64
+	}
+)
+
+func NewExponentialBinner(maxSize int, maxScale int32) ExponentialBinner {
+	return ExponentialBinner{
+		MaxScale: maxScale,
+		MaxSize:  maxSize,
+	}
+}
+
+func (b ExponentialBinner) BinPoints(xys plotter.XYer, _ DataTransformer) []HistogramBin {
+	return b.bin(xys).bins()
+}
+
+// Export returns the OTLP histogram of the points, rounding their
+// weights to whole counts.
+func (b ExponentialBinner) Export(xys plotter.XYer) ExponentialHistogram {
+	return b.bin(xys).export()
+}
+
+// bin counts the points in buckets of the largest scale at which
+// they fit.
+func (b ExponentialBinner) bin(xys plotter.XYer) exponential {
+	maxScale := min(max(b.MaxScale, MinExponentialScale), MaxExponentialScale)
+	maxSize := b.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultExponentialSize
+	}
+
+	// Index the values at the maximum scale.
+	type point struct {
+		index  int32
+		weight float64
+	}
+	var pos, neg []point
+	e := exponential{zeroThreshold: b.ZeroThreshold}
+	for i := 0; i < xys.Len(); i++ {
+		x, w := xys.XY(i)
+		e.sum += x * w
+		switch {
+		case math.Abs(x) <= b.ZeroThreshold:
+			e.zero += w
+		case x > 0:
+			pos = append(pos, point{exponentialIndex(x, maxScale), w})
+		default:
+			neg = append(neg, point{exponentialIndex(-x, maxScale), w})
+		}
+	}
+
+	// Downscale until each sign fits.
+	shift := int32(0)
+	for _, pts := range [][]point{pos, neg} {
+		if len(pts) == 0 {
+			continue
+		}
+		lo, hi := pts[0].index, pts[0].index
+		for _, p := range pts {
+			lo = min(lo, p.index)
+			hi = max(hi, p.index)
+		}
+		for maxScale-shift > MinExponentialScale && int((hi>>shift)-(lo>>shift)) >= maxSize {
+			shift++
+		}
+	}
+	e.scale = maxScale - shift
+
+	fill := func(pts []point) expBuckets {
+		if len(pts) == 0 {
+			return expBuckets{}
+		}
+		lo, hi := pts[0].index>>shift, pts[0].index>>shift
+		for _, p := range pts {
+			lo = min(lo, p.index>>shift)
+			hi = max(hi, p.index>>shift)
+		}
+		eb := expBuckets{offset: lo, counts: make([]float64, hi-lo+1)}
+		for _, p := range pts {
+			eb.counts[(p.index>>shift)-lo] += p.weight
+		}
+		return eb
+	}
+	e.positive = fill(pos)
+	e.negative = fill(neg)
+	return e
+}
+
+// exponentialIndex returns the bucket of a positive value, exactly
+// for powers of two, which are the upper bounds of their buckets.
+func exponentialIndex(value float64, scale int32) int32 {
+	frac, exp := math.Frexp(value)
+	if scale <= 0 {
+		if frac == 0.5 {
+			exp--
+		}
+		return int32(exp-1) >> -scale
+	}
+	if frac == 0.5 {
+		return int32(exp-1)<<scale - 1
+	}
+	return int32(math.Floor(math.Log(value) * math.Ldexp(math.Log2E, int(scale))))
+}
+
+// exponentialBound returns the lower bound of bucket index.
+func exponentialBound(index, scale int32) float64 {
+	return math.Exp2(math.Ldexp(float64(index), -int(scale)))
+}
+
+// bins returns the negative buckets, then the zero bucket if it has
+// weight, then the positive buckets, in order of value.  Each bin's
+// Sum places its weight at the midpoint.
+func (e exponential) bins() []HistogramBin {
+	var bins []HistogramBin
+	add := func(min, max, weight float64) {
+		bins = append(bins, HistogramBin{
+			Min:    min,
+			Max:    max,
+			Weight: weight,
+			Sum:    weight * (min + max) / 2,
+		})
+	}
+	for j := len(e.negative.counts) - 1; j >= 0; j-- {
+		index := e.negative.offset + int32(j)
+		add(-exponentialBound(index+1, e.scale), -exponentialBound(index, e.scale), e.negative.counts[j])
+	}
+	if e.zero != 0 {
+		add(-e.zeroThreshold, e.zeroThreshold, e.zero)
+	}
+	for j, count := range e.positive.counts {
+		index := e.positive.offset + int32(j)
+		add(exponentialBound(index, e.scale), exponentialBound(index+1, e.scale), count)
+	}
+	return bins
+}
+
+func (e exponential) export() ExponentialHistogram {
+	round := func(w float64) uint64 {
+		return uint64(math.Round(w))
+	}
+	buckets := func(eb expBuckets) ExponentialBuckets {
+		out := ExponentialBuckets{Offset: eb.offset}
+		for _, c := range eb.counts {
+			out.BucketCounts = append(out.BucketCounts, round(c))
+		}
+		return out
+	}
+	h := ExponentialHistogram{
+		Scale:         e.scale,
+		ZeroThreshold: e.zeroThreshold,
+		ZeroCount:     round(e.zero),
+		Positive:      buckets(e.positive),
+		Negative:      buckets(e.negative),
+		Sum:           e.sum,
+	}
+	h.Count = h.ZeroCount
+	for _, c := range append(h.Positive.BucketCounts, h.Negative.BucketCounts...) {
+		h.Count += c
+	}
+	return h
+}
+
+// Bins imports an OTLP histogram as bins, as from BinPoints.
+func (h ExponentialHistogram) Bins() ([]HistogramBin, error) {
+	if h.Scale < MinExponentialScale || h.Scale > MaxExponentialScale {
+		return nil, fmt.Errorf("exponential histogram scale %d out of range", h.Scale)
+	}
+	imp := func(b ExponentialBuckets) expBuckets {
+		eb := expBuckets{offset: b.Offset}
+		for _, c := range b.BucketCounts {
+			eb.counts = append(eb.counts, float64(c))
+		}
+		return eb
+	}
+	return exponential{
+		scale:         h.Scale,
+		zeroThreshold: h.ZeroThreshold,
+		zero:          float64(h.ZeroCount),
+		positive:      imp(h.Positive),
+		negative:      imp(h.Negative),
+		sum:           h.Sum,
+	}.bins(), nil
+}
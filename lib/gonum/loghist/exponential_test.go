@@ -0,0 +1,82 @@
+package loghist
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/plot/plotter"
+)
+
+func TestExponentialIndex(t *testing.T) {
+	// At scale 0, bucket i holds (2^i, 2^(i+1)].
+	require.Equal(t, int32(-1), exponentialIndex(1, 0))
+	require.Equal(t, int32(0), exponentialIndex(1.5, 0))
+	require.Equal(t, int32(0), exponentialIndex(2, 0))
+	require.Equal(t, int32(1), exponentialIndex(3, 0))
+	require.Equal(t, int32(-2), exponentialIndex(0.5, 0))
+
+	// At scale -1 the base is 4.
+	require.Equal(t, int32(0), exponentialIndex(4, -1))
+	require.Equal(t, int32(1), exponentialIndex(5, -1))
+
+	// At scale 1 the base is √2.
+	require.Equal(t, int32(1), exponentialIndex(2, 1))
+	require.Equal(t, int32(2), exponentialIndex(2.5, 1))
+	require.Equal(t, int32(3), exponentialIndex(3, 1))
+
+	for scale := int32(MinExponentialScale); scale <= MaxExponentialScale; scale++ {
+		for _, v := range []float64{0.001, 0.3, 1, 7, 1e6} {
+			i := exponentialIndex(v, scale)
+			require.Less(t, exponentialBound(i, scale), v*(1+1e-12), "scale %d value %g", scale, v)
+			require.GreaterOrEqual(t, exponentialBound(i+1, scale), v*(1-1e-12), "scale %d value %g", scale, v)
+		}
+	}
+}
+
+func TestExponentialDownscale(t *testing.T) {
+	var xys plotter.XYs
+	for v := 1.0; v < 1e6; v *= 1.1 {
+		xys = append(xys, plotter.XY{X: v, Y: 1})
+	}
+	h := NewExponentialBinner(20, MaxExponentialScale).Export(xys)
+	require.LessOrEqual(t, len(h.Positive.BucketCounts), 20)
+	require.Equal(t, uint64(len(xys)), h.Count)
+
+	// One more scale would not fit.
+	wider := NewExponentialBinner(20, h.Scale+1).Export(xys)
+	require.Equal(t, h.Scale, wider.Scale)
+	require.Greater(t, len(NewExponentialBinner(1000, h.Scale+1).Export(xys).Positive.BucketCounts), 20)
+}
+
+func TestExponentialSigns(t *testing.T) {
+	xys := plotter.XYs{{X: -3, Y: 1}, {X: -1, Y: 2}, {X: 0, Y: 4}, {X: 0.001, Y: 8}, {X: 3, Y: 16}}
+	b := ExponentialBinner{MaxScale: 0, MaxSize: 160, ZeroThreshold: 0.01}
+	h := b.Export(xys)
+
+	require.Equal(t, uint64(12), h.ZeroCount)
+	require.Equal(t, ExponentialBuckets{Offset: -1, BucketCounts: []uint64{2, 0, 1}}, h.Negative)
+	require.Equal(t, ExponentialBuckets{Offset: 1, BucketCounts: []uint64{16}}, h.Positive)
+	require.Equal(t, uint64(31), h.Count)
+
+	bins := b.BinPoints(xys, LinearTransformer{})
+	for i := 1; i < len(bins); i++ {
+		require.LessOrEqual(t, bins[i-1].Max, bins[i].Min)
+	}
+	require.Equal(t, HistogramBin{Min: -4, Max: -2, Weight: 1, Sum: -3}, bins[0])
+	require.Equal(t, HistogramBin{Min: 2, Max: 4, Weight: 16, Sum: 48}, bins[len(bins)-1])
+}
+
+func TestExponentialRoundTrip(t *testing.T) {
+	var xys plotter.XYs
+	for i := 1; i <= 1000; i++ {
+		xys = append(xys, plotter.XY{X: math.Pow(1.01, float64(i)) - 5, Y: 1})
+	}
+	b := NewExponentialBinner(DefaultExponentialSize, MaxExponentialScale)
+	imported, err := b.Export(xys).Bins()
+	require.NoError(t, err)
+	require.Equal(t, b.BinPoints(xys, nil), imported)
+
+	_, err = ExponentialHistogram{Scale: 21}.Bins()
+	require.Error(t, err)
+}
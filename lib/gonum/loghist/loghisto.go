@@ -36,13 +36,18 @@ type (
 
 		// If true, bars are placed at their centroid, not strictly
 		// adjacent to each other.
-		centroids bool
-		normalize float64
+		centroids   bool
+		norm        Normalization
+		normalizeTo float64
 
 		TotalWidth float64
 		SumWeight  float64
 	}
 
+	// Normalization selects the height of the bars of a
+	// histogram.
+	Normalization int
+
 	DataTransformer interface {
 		Transform(float64) float64
 		Invert(float64) float64
@@ -75,6 +80,25 @@ type (
 	}
 )
 
+const (
+	// NormalizeCount draws the weight of each bin.
+	NormalizeCount Normalization = iota
+
+	// NormalizeProbability draws the fraction of the total
+	// weight in each bin.
+	NormalizeProbability
+
+	// NormalizeDensity draws the fraction of the total weight
+	// per unit width of each bin, estimating the density of X.
+	NormalizeDensity
+
+	// NormalizeTransformedDensity draws the fraction of the total
+	// weight per unit width in the transformed space, estimating
+	// the density of log X for a LogTransformer.  On a log axis
+	// its areas are proportional to probability.
+	NormalizeTransformedDensity
+)
+
 func NewFixedBinner(count int, min, max float64) FixedBinner {
 	return FixedBinner{
 		Count: count,
@@ -105,14 +129,15 @@ func NewHistogram(transform DataTransformer, bins []HistogramBin) *Histogram {
 		weight += b.Weight
 	}
 	return &Histogram{
-		Bins:       bins,
-		Transform:  transform,
-		FillColors: nil,
-		LineStyle:  plotter.DefaultLineStyle,
-		centroids:  false,
-		SumWeight:  weight,
-		TotalWidth: width,
-		normalize:  weight,
+		Bins:        bins,
+		Transform:   transform,
+		FillColors:  nil,
+		LineStyle:   plotter.DefaultLineStyle,
+		centroids:   false,
+		SumWeight:   weight,
+		TotalWidth:  width,
+		norm:        NormalizeCount,
+		normalizeTo: 1,
 	}
 }
 
@@ -162,10 +187,43 @@ func (h *Histogram) coords(bin HistogramBin) (min, max, height float64) {
 	} else {
 		center = bin.Min + half
 	}
-	//height = (bin.Weight / h.SumWeight) / width
-	// @@@ WTF * h.normalize
-	height = bin.Weight
-	return center - half, center + half, height
+	return center - half, center + half, h.Height(bin)
+}
+
+// Height returns the height of the bar of a bin.
+func (h *Histogram) Height(bin HistogramBin) float64 {
+	return h.norm.height(bin, h.SumWeight, h.normalizeTo, h.Transform)
+}
+
+// height returns the height of a bin in a histogram of the given
+// total weight, scaled so the probabilities sum to `to`.  Bins of
+// no width have no density.
+func (n Normalization) height(bin HistogramBin, total, to float64, transform DataTransformer) float64 {
+	if n == NormalizeCount {
+		return bin.Weight
+	}
+	if total == 0 {
+		return 0
+	}
+	prob := to * bin.Weight / total
+	var width float64
+	switch n {
+	case NormalizeProbability:
+		return prob
+	case NormalizeDensity:
+		width = bin.Max - bin.Min
+	case NormalizeTransformedDensity:
+		if transform == nil {
+			transform = LinearTransformer{}
+		}
+		width = transform.Transform(bin.Max) - transform.Transform(bin.Min)
+	default:
+		panic(fmt.Sprintf("unknown normalization %d", n))
+	}
+	if width <= 0 {
+		return 0
+	}
+	return prob / width
 }
 
 // DataRange returns the minimum and maximum X and Y values
@@ -195,7 +253,14 @@ func (h *Histogram) Centroids() {
 // Normalize normalizes the histogram so that the
 // total area beneath it sums to a given value.
 func (h *Histogram) Normalize(to float64) {
-	h.normalize = to
+	h.NormalizeAs(NormalizeDensity, to)
+}
+
+// NormalizeAs selects the heights of the bars, with the total
+// probability scaled to a given value.
+func (h *Histogram) NormalizeAs(norm Normalization, to float64) {
+	h.norm = norm
+	h.normalizeTo = to
 }
 
 // Thumbnail draws a rectangle in the given style of the histogram.
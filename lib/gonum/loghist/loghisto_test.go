@@ -0,0 +1,137 @@
+package loghist
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/plot/plotter"
+)
+
+const analyticSize = 200000
+
+func sample(draw func(*rand.Rand) float64) plotter.XYs {
+	rnd := rand.New(rand.NewSource(1))
+	xys := make(plotter.XYs, analyticSize)
+	for i := range xys {
+		xys[i] = plotter.XY{X: draw(rnd), Y: 1}
+	}
+	return xys
+}
+
+func sampleRange(xys plotter.XYs) (lo, hi float64) {
+	lo, hi = math.Inf(+1), math.Inf(-1)
+	for _, xy := range xys {
+		lo = math.Min(lo, xy.X)
+		hi = math.Max(hi, xy.X)
+	}
+	return lo, hi
+}
+
+// area returns the sum of the heights times the widths of the bins,
+// in the transformed space.
+func area(h *Histogram, transform DataTransformer) float64 {
+	total := 0.0
+	for _, bin := range h.Bins {
+		total += h.Height(bin) * (transform.Transform(bin.Max) - transform.Transform(bin.Min))
+	}
+	return total
+}
+
+func TestNormalizeUniform(t *testing.T) {
+	xys := sample((*rand.Rand).Float64)
+	bins := NewFixedBinner(10, 0, 1).BinPoints(xys, LinearTransformer{})
+	h := NewHistogram(LinearTransformer{}, bins)
+
+	for _, bin := range bins {
+		require.InEpsilon(t, analyticSize/10, h.Height(bin), 0.05)
+	}
+	h.NormalizeAs(NormalizeProbability, 1)
+	for _, bin := range bins {
+		require.InEpsilon(t, 0.1, h.Height(bin), 0.05)
+	}
+	h.Normalize(1)
+	for _, bin := range bins {
+		require.InEpsilon(t, 1, h.Height(bin), 0.05)
+	}
+	require.InDelta(t, 1, area(h, LinearTransformer{}), 1e-9)
+
+	h.Normalize(2)
+	require.InDelta(t, 2, area(h, LinearTransformer{}), 1e-9)
+}
+
+func TestNormalizeExponentialLog(t *testing.T) {
+	xys := sample((*rand.Rand).ExpFloat64)
+	lo, hi := sampleRange(xys)
+	bins := NewFixedBinner(40, lo, hi).BinPoints(xys, LogTransformer{})
+	h := NewHistogram(LogTransformer{}, bins)
+
+	cdf := func(x float64) float64 { return 1 - math.Exp(-x) }
+
+	// Each bin's density is the mean of the pdf over the bin, of
+	// X or of log X.
+	h.Normalize(1)
+	for _, bin := range bins {
+		p := cdf(bin.Max) - cdf(bin.Min)
+		if p*analyticSize < 1000 {
+			continue
+		}
+		require.InEpsilon(t, p/(bin.Max-bin.Min), h.Height(bin), 0.1)
+	}
+	require.InDelta(t, 1, area(h, LinearTransformer{}), 1e-9)
+
+	h.NormalizeAs(NormalizeTransformedDensity, 1)
+	for _, bin := range bins {
+		p := cdf(bin.Max) - cdf(bin.Min)
+		if p*analyticSize < 1000 {
+			continue
+		}
+		require.InEpsilon(t, p/math.Log(bin.Max/bin.Min), h.Height(bin), 0.1)
+	}
+	require.InDelta(t, 1, area(h, LogTransformer{}), 1e-9)
+}
+
+func TestNormalizeVariableWidth(t *testing.T) {
+	xys := sample((*rand.Rand).ExpFloat64)
+	bins := NewExponentialBinner(40, 20).BinPoints(xys, nil)
+	h := NewHistogram(LinearTransformer{}, bins)
+	h.Normalize(1)
+	require.InDelta(t, 1, area(h, LinearTransformer{}), 1e-9)
+
+	// The bins widen with X, and each density is the mean of the
+	// pdf over its bin.
+	cdf := func(x float64) float64 { return 1 - math.Exp(-x) }
+	for _, bin := range bins {
+		p := cdf(bin.Max) - cdf(bin.Min)
+		if p*analyticSize < 1000 {
+			continue
+		}
+		require.InEpsilon(t, p/(bin.Max-bin.Min), h.Height(bin), 0.1)
+	}
+}
+
+func TestNormalizeStacked(t *testing.T) {
+	xys := sample((*rand.Rand).Float64)
+	binner := NewFixedBinner(10, 0, 1)
+	low := binner.BinPoints(xys[:analyticSize/4], LinearTransformer{})
+	high := binner.BinPoints(xys[analyticSize/4:], LinearTransformer{})
+	sh, err := NewStackedHistogram([][]HistogramBin{low, high})
+	require.NoError(t, err)
+
+	// Stacked densities are the densities of all the data, split
+	// in proportion to weight.
+	sh.NormalizeAs(NormalizeDensity, 1)
+	for i := range low {
+		bases, tops := sh.segments(i)
+		require.Equal(t, 0.0, bases[0])
+		require.InEpsilon(t, 0.25, tops[0], 0.1)
+		require.InEpsilon(t, 1, tops[1], 0.05)
+	}
+
+	sh.Normalize()
+	for i := range low {
+		_, tops := sh.segments(i)
+		require.InDelta(t, 1, tops[1], 1e-9)
+	}
+}
@@ -0,0 +1,226 @@
+package loghist
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+const (
+	// maxRuleBins limits the bins chosen by any rule.
+	maxRuleBins = 10000
+
+	// maxShimazakiSearch limits the bins tried by the
+	// Shimazaki–Shinomoto rule.
+	maxShimazakiSearch = 500
+)
+
+type (
+	// BinRule chooses a number of bins for a sample, given in the
+	// transformed space sorted by value.  Each weight counts as
+	// that many observations.  The rules here choose one bin for
+	// an empty or constant sample.
+	BinRule func(sample []WeightedValue) int
+
+	// WeightedValue is one observation of a sample.
+	WeightedValue struct {
+		Value, Weight float64
+	}
+
+	// RuleBinner divides the range of the data into bins of equal
+	// width in the transformed space, as many as its rule chooses.
+	RuleBinner struct {
+		Rule BinRule
+	}
+)
+
+// NewSturgesBinner chooses 1 + log₂ n bins, which suits normal data
+// of moderate size.
+func NewSturgesBinner() RuleBinner {
+	return RuleBinner{Rule: Sturges}
+}
+
+// NewRiceBinner chooses 2 n^⅓ bins.
+func NewRiceBinner() RuleBinner {
+	return RuleBinner{Rule: Rice}
+}
+
+// NewScottBinner chooses bins of width 3.49 σ n^-⅓, which is optimal
+// for normal data.
+func NewScottBinner() RuleBinner {
+	return RuleBinner{Rule: Scott}
+}
+
+// NewFreedmanDiaconisBinner chooses bins of width 2 IQR n^-⅓, which
+// is robust to outliers.
+func NewFreedmanDiaconisBinner() RuleBinner {
+	return RuleBinner{Rule: FreedmanDiaconis}
+}
+
+// NewDoaneBinner extends Sturges' rule with bins for the skewness
+// of the data.
+func NewDoaneBinner() RuleBinner {
+	return RuleBinner{Rule: Doane}
+}
+
+// NewShimazakiShinomotoBinner chooses the number of bins that
+// minimizes the estimated error of the histogram as a density.
+func NewShimazakiShinomotoBinner() RuleBinner {
+	return RuleBinner{Rule: ShimazakiShinomoto}
+}
+
+func (r RuleBinner) BinPoints(xys plotter.XYer, transform DataTransformer) []HistogramBin {
+	sample := make([]WeightedValue, xys.Len())
+	xmin, xmax := math.Inf(+1), math.Inf(-1)
+	for i := range sample {
+		x, y := xys.XY(i)
+		xmin = math.Min(xmin, x)
+		xmax = math.Max(xmax, x)
+		sample[i] = WeightedValue{Value: transform.Transform(x), Weight: y}
+	}
+	sort.Slice(sample, func(i, j int) bool {
+		return sample[i].Value < sample[j].Value
+	})
+	count := 1
+	if len(sample) != 0 && xmax > xmin {
+		count = max(1, min(r.Rule(sample), maxRuleBins))
+	}
+	return FixedBinner{Count: count, Min: xmin, Max: xmax}.binPoints(xys, transform)
+}
+
+func Sturges(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	return int(math.Ceil(math.Log2(totalWeight(sample)))) + 1
+}
+
+func Rice(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	return int(math.Ceil(2 * math.Cbrt(totalWeight(sample))))
+}
+
+func Scott(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	_, sd, _ := moments(sample)
+	return widthRule(sample, 3.49*sd)
+}
+
+func FreedmanDiaconis(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	iqr := quantile(sample, 0.75) - quantile(sample, 0.25)
+	return widthRule(sample, 2*iqr)
+}
+
+func Doane(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	n := totalWeight(sample)
+	if n <= 2 {
+		return Sturges(sample)
+	}
+	_, _, skew := moments(sample)
+	sigma := math.Sqrt(6 * (n - 2) / ((n + 1) * (n + 3)))
+	return int(math.Ceil(1 + math.Log2(n) + math.Log2(1+math.Abs(skew)/sigma)))
+}
+
+// ShimazakiShinomoto minimizes (2 mean - variance) / width² of the
+// bin weights, over equal-width bins.
+func ShimazakiShinomoto(sample []WeightedValue) int {
+	if degenerate(sample) {
+		return 1
+	}
+	lo, hi := sample[0].Value, sample[len(sample)-1].Value
+	best, bestCost := 1, math.Inf(+1)
+	buf := make([]float64, maxShimazakiSearch)
+	for k := 1; k <= maxShimazakiSearch; k++ {
+		width := (hi - lo) / float64(k)
+		counts := buf[:k]
+		for i := range counts {
+			counts[i] = 0
+		}
+		for _, s := range sample {
+			i := min(int((s.Value-lo)/width), k-1)
+			counts[i] += s.Weight
+		}
+		var sum, sumSq float64
+		for _, c := range counts {
+			sum += c
+			sumSq += c * c
+		}
+		mean := sum / float64(k)
+		variance := sumSq/float64(k) - mean*mean
+		if cost := (2*mean - variance) / (width * width); cost < bestCost {
+			best, bestCost = k, cost
+		}
+	}
+	return best
+}
+
+// widthRule is the number of bins of the given width spanning the
+// sample, scaled by n^-⅓, or Sturges' rule if the width is zero.
+func widthRule(sample []WeightedValue, width float64) int {
+	width *= math.Pow(totalWeight(sample), -1.0/3)
+	if width <= 0 {
+		return Sturges(sample)
+	}
+	return int(math.Ceil((sample[len(sample)-1].Value - sample[0].Value) / width))
+}
+
+// degenerate is true of a sample without weight or spread, which
+// has one bin.
+func degenerate(sample []WeightedValue) bool {
+	return len(sample) == 0 || totalWeight(sample) <= 0 || sample[0].Value == sample[len(sample)-1].Value
+}
+
+func totalWeight(sample []WeightedValue) float64 {
+	total := 0.0
+	for _, s := range sample {
+		total += s.Weight
+	}
+	return total
+}
+
+// moments returns the mean, standard deviation and skewness of the
+// sample.
+func moments(sample []WeightedValue) (mean, sd, skew float64) {
+	n := totalWeight(sample)
+	for _, s := range sample {
+		mean += s.Weight * s.Value
+	}
+	mean /= n
+	var m2, m3 float64
+	for _, s := range sample {
+		d := s.Value - mean
+		m2 += s.Weight * d * d
+		m3 += s.Weight * d * d * d
+	}
+	m2 /= n
+	m3 /= n
+	if m2 == 0 {
+		return mean, 0, 0
+	}
+	return mean, math.Sqrt(m2), m3 / math.Pow(m2, 1.5)
+}
+
+// quantile returns the least value with at least the fraction q of
+// the weight at or below it.
+func quantile(sample []WeightedValue, q float64) float64 {
+	target := q * totalWeight(sample)
+	cum := 0.0
+	for _, s := range sample {
+		cum += s.Weight
+		if cum >= target {
+			return s.Value
+		}
+	}
+	return sample[len(sample)-1].Value
+}
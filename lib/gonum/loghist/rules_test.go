@@ -0,0 +1,89 @@
+package loghist
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/plot/plotter"
+)
+
+func normalSample(n int) plotter.XYs {
+	rnd := rand.New(rand.NewSource(1))
+	xys := make(plotter.XYs, n)
+	for i := range xys {
+		xys[i] = plotter.XY{X: rnd.NormFloat64(), Y: 1}
+	}
+	return xys
+}
+
+func sumWeight(bins []HistogramBin) float64 {
+	total := 0.0
+	for _, b := range bins {
+		total += b.Weight
+	}
+	return total
+}
+
+func TestRuleCounts(t *testing.T) {
+	xys := normalSample(1000)
+
+	require.Len(t, NewSturgesBinner().BinPoints(xys, LinearTransformer{}), 11)
+	require.Len(t, NewRiceBinner().BinPoints(xys, LinearTransformer{}), 20)
+
+	// For normal data the Scott and Freedman–Diaconis widths are
+	// close, and Doane adds little to Sturges.
+	scott := len(NewScottBinner().BinPoints(xys, LinearTransformer{}))
+	fd := len(NewFreedmanDiaconisBinner().BinPoints(xys, LinearTransformer{}))
+	require.InDelta(t, scott, fd, 0.25*float64(scott))
+	require.InDelta(t, 11, len(NewDoaneBinner().BinPoints(xys, LinearTransformer{})), 2)
+
+	for _, b := range []Binner{
+		NewSturgesBinner(),
+		NewRiceBinner(),
+		NewScottBinner(),
+		NewFreedmanDiaconisBinner(),
+		NewDoaneBinner(),
+		NewShimazakiShinomotoBinner(),
+	} {
+		require.InDelta(t, 1000, sumWeight(b.BinPoints(xys, LinearTransformer{})), 1e-9)
+	}
+}
+
+func TestRuleWeights(t *testing.T) {
+	// Weights count as repeated observations.
+	repeated := append(normalSample(500), normalSample(500)...)
+	weighted := normalSample(500)
+	for i := range weighted {
+		weighted[i].Y = 2
+	}
+	for _, b := range []RuleBinner{NewSturgesBinner(), NewScottBinner(), NewFreedmanDiaconisBinner(), NewDoaneBinner()} {
+		require.Equal(t,
+			len(b.BinPoints(repeated, LinearTransformer{})),
+			len(b.BinPoints(weighted, LinearTransformer{})))
+	}
+}
+
+func TestRuleLogTransform(t *testing.T) {
+	xys := normalSample(1000)
+	for i := range xys {
+		xys[i].X = math.Exp(xys[i].X)
+	}
+	bins := NewFreedmanDiaconisBinner().BinPoints(xys, LogTransformer{})
+	require.InDelta(t, 1000, sumWeight(bins), 1e-9)
+
+	// The bins are of equal width in the logarithm.
+	ratio := bins[0].Max / bins[0].Min
+	for _, b := range bins {
+		require.InEpsilon(t, ratio, b.Max/b.Min, 1e-9)
+	}
+}
+
+func TestRuleDegenerateSamples(t *testing.T) {
+	constant := []WeightedValue{{Value: 3, Weight: 1}, {Value: 3, Weight: 2}}
+	for _, rule := range []BinRule{Sturges, Rice, Scott, FreedmanDiaconis, Doane, ShimazakiShinomoto} {
+		require.Equal(t, 1, rule(nil))
+		require.Equal(t, 1, rule(constant))
+	}
+}
@@ -0,0 +1,170 @@
+package loghist
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type (
+	// StackedHistogram implements the Plotter interface, drawing
+	// the histograms of several categories over the same bins,
+	// with each bin split by category.
+	StackedHistogram struct {
+		// Categories holds the bins of each category, in
+		// stacking order from the axis.
+		Categories [][]HistogramBin
+
+		// FillColors holds the color of each category.
+		FillColors []color.Color
+
+		// Transform is used by NormalizeTransformedDensity.
+		Transform DataTransformer
+
+		// LineStyle is the style of the outline of each
+		// segment of each bar.
+		draw.LineStyle
+
+		// If true, each bin is stacked to one, showing the
+		// fraction of its weight in each category.
+		normalized bool
+
+		norm        Normalization
+		normalizeTo float64
+		sumWeight   float64
+	}
+
+	categoryThumbnail struct {
+		*StackedHistogram
+		category int
+	}
+)
+
+// NewStackedHistogram returns a histogram stacking the categories,
+// whose bins must have the same bounds, as from a FixedBinner.
+func NewStackedHistogram(categories [][]HistogramBin) (*StackedHistogram, error) {
+	if len(categories) == 0 {
+		return nil, fmt.Errorf("stacked histogram has no categories")
+	}
+	for c, bins := range categories[1:] {
+		if len(bins) != len(categories[0]) {
+			return nil, fmt.Errorf("category %d has %d bins, not %d", c+1, len(bins), len(categories[0]))
+		}
+		for i, bin := range bins {
+			if bin.Min != categories[0][i].Min || bin.Max != categories[0][i].Max {
+				return nil, fmt.Errorf("category %d bin %d bounds differ", c+1, i)
+			}
+		}
+	}
+	weight := 0.0
+	for _, bins := range categories {
+		for _, bin := range bins {
+			weight += bin.Weight
+		}
+	}
+	return &StackedHistogram{
+		Categories:  categories,
+		LineStyle:   plotter.DefaultLineStyle,
+		norm:        NormalizeCount,
+		normalizeTo: 1,
+		sumWeight:   weight,
+	}, nil
+}
+
+// Normalize stacks each bin to one.
+func (s *StackedHistogram) Normalize() {
+	s.normalized = true
+}
+
+// NormalizeAs selects the heights of the bars as for a Histogram
+// of all the categories, whose segments divide each bar by weight.
+func (s *StackedHistogram) NormalizeAs(norm Normalization, to float64) {
+	s.norm = norm
+	s.normalizeTo = to
+}
+
+// segments returns the base and top of each category in bin i.
+// Stacking to one takes precedence over NormalizeAs.
+func (s *StackedHistogram) segments(i int) (bases, tops []float64) {
+	total := 0.0
+	for _, bins := range s.Categories {
+		total += bins[i].Weight
+	}
+	height := 0.0
+	for _, bins := range s.Categories {
+		bases = append(bases, height)
+		switch {
+		case !s.normalized:
+			height += s.norm.height(bins[i], s.sumWeight, s.normalizeTo, s.Transform)
+		case total != 0:
+			height += bins[i].Weight / total
+		}
+		tops = append(tops, height)
+	}
+	return bases, tops
+}
+
+// Plot implements the Plotter interface, drawing a segment of each
+// bar for each category.
+func (s *StackedHistogram) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+
+	for i, bin := range s.Categories[0] {
+		bases, tops := s.segments(i)
+		for cat := range s.Categories {
+			if tops[cat] == bases[cat] {
+				continue
+			}
+			pts := []vg.Point{
+				{X: trX(bin.Min), Y: trY(bases[cat])},
+				{X: trX(bin.Max), Y: trY(bases[cat])},
+				{X: trX(bin.Max), Y: trY(tops[cat])},
+				{X: trX(bin.Min), Y: trY(tops[cat])},
+			}
+			if s.FillColors != nil {
+				c.FillPolygon(s.FillColors[cat], c.ClipPolygonXY(pts))
+			}
+			pts = append(pts, pts[0])
+			c.StrokeLines(s.LineStyle, c.ClipLinesXY(pts)...)
+		}
+	}
+}
+
+// DataRange returns the minimum and maximum X and Y values
+func (s *StackedHistogram) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = math.Inf(+1)
+	xmax = math.Inf(-1)
+	ymax = math.Inf(-1)
+	for i, bin := range s.Categories[0] {
+		_, tops := s.segments(i)
+		xmin = math.Min(xmin, bin.Min)
+		xmax = math.Max(xmax, bin.Max)
+		ymax = math.Max(ymax, tops[len(tops)-1])
+	}
+	return
+}
+
+// CategoryThumbnail returns the legend thumbnail of a category.
+func (s *StackedHistogram) CategoryThumbnail(category int) plot.Thumbnailer {
+	return categoryThumbnail{s, category}
+}
+
+// Thumbnail draws a rectangle in the style of the category.
+func (t categoryThumbnail) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+	}
+	if t.FillColors != nil {
+		c.FillPolygon(t.FillColors[t.category], c.ClipPolygonXY(pts))
+	}
+	pts = append(pts, pts[0])
+	c.StrokeLines(t.LineStyle, c.ClipLinesXY(pts)...)
+}
@@ -0,0 +1,197 @@
+package essay
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const defaultImageBudget = 1 << 20
+
+var leftoverText = regexp.MustCompile(`TODO|@@@`)
+
+type (
+	// LintConfig sets the limits checked by the LintBackend.
+	LintConfig struct {
+		// PageWidth is the widest image allowed, in CSS
+		// pixels after Config.ImageScale.  Zero means the
+		// page width of the theme.
+		PageWidth int
+
+		// ImageBudget is the largest encoded image allowed,
+		// in bytes.  Zero means 1 MiB.
+		ImageBudget int
+	}
+
+	// linter walks the essay without writing it, reporting
+	// problems with their section paths.
+	linter struct {
+		essay    *Essay
+		frames   []lintFrame
+		callouts []string
+		problems []string
+	}
+
+	// lintFrame is an open section or tab.
+	lintFrame struct {
+		section bool
+		items   int
+		names   map[string]bool
+	}
+)
+
+func writeLint(e *Essay) error {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	l := &linter{essay: e}
+	if err := e.walkNode(l, sectionNode, e.config.Title, e.contents()); err != nil {
+		return err
+	}
+	for _, problem := range l.problems {
+		if _, err := io.WriteString(e.out, problem+"\n"); err != nil {
+			return err
+		}
+	}
+	if len(l.problems) != 0 {
+		return fmt.Errorf("%d lint problems", len(l.problems))
+	}
+	return nil
+}
+
+func (l *linter) report(format string, args ...interface{}) {
+	path := l.essay.sectionPath()
+	if path == "" {
+		path = l.essay.config.Title
+	}
+	l.problems = append(l.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (l *linter) top() *lintFrame {
+	return &l.frames[len(l.frames)-1]
+}
+
+// item counts content toward the innermost section.
+func (l *linter) item() {
+	if len(l.frames) != 0 {
+		l.top().items++
+	}
+}
+
+func (l *linter) enter(kind nodeKind, name string) error {
+	switch kind {
+	case sectionNode, tabNode:
+		if kind == sectionNode && len(l.frames) != 0 {
+			if l.top().names[name] {
+				l.report("duplicate section title %q", name)
+			}
+			l.top().names[name] = true
+			l.leftover("section title", name)
+		}
+		l.frames = append(l.frames, lintFrame{
+			section: kind == sectionNode,
+			names:   map[string]bool{},
+		})
+	case calloutNode:
+		if name == string(TodoCallout) && l.essay.config.Todo == TodoIgnore {
+			l.report("TODO callout")
+		}
+		l.callouts = append(l.callouts, name)
+	}
+	return nil
+}
+
+func (l *linter) leave(kind nodeKind) error {
+	switch kind {
+	case sectionNode, tabNode:
+		frame := l.frames[len(l.frames)-1]
+		l.frames = l.frames[:len(l.frames)-1]
+		if frame.section && frame.items == 0 {
+			l.report("empty section")
+		}
+		if frame.items != 0 {
+			l.item()
+		}
+	case calloutNode:
+		l.callouts = l.callouts[:len(l.callouts)-1]
+	}
+	return nil
+}
+
+func (l *linter) text(s string) error {
+	if strings.TrimSpace(s) != "" {
+		l.item()
+	}
+	l.leftover("text", flowText(s))
+	return nil
+}
+
+func (l *linter) html(h template.HTML) error {
+	return l.text(htmlTag.ReplaceAllString(string(h), " "))
+}
+
+// leftover reports TODO and @@@ markers outside of TODO callouts,
+// which are reported whole, here or by TodoMode.
+func (l *linter) leftover(what, s string) {
+	for _, kind := range l.callouts {
+		if kind == string(TodoCallout) {
+			return
+		}
+	}
+	if m := leftoverText.FindString(s); m != "" {
+		l.report("%s contains %q: %s", what, m, s)
+	}
+}
+
+func (l *linter) Theme() Theme {
+	return l.essay.theme
+}
+
+func (l *linter) PixelRatio() float64 {
+	return l.essay.PixelRatio()
+}
+
+func (l *linter) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	l.item()
+
+	conf := l.essay.config.Lint
+	pageWidth := conf.PageWidth
+	if pageWidth == 0 {
+		pageWidth = l.essay.theme.PageWidth
+	}
+	budget := conf.ImageBudget
+	if budget == 0 {
+		budget = defaultImageBudget
+	}
+
+	desc := fmt.Sprintf("%s image %dx%d", img.Kind, img.Bounds.Dx(), img.Bounds.Dy())
+	if img.Alt == "" && img.Caption == "" {
+		l.report("%s has no alt text or caption", desc)
+	}
+	if width, _ := l.essay.displaySize(img.Bounds); width > pageWidth {
+		l.report("%s is %dpx wide, wider than the %dpx page", desc, width, pageWidth)
+	}
+	if len(img.Data) > budget {
+		l.report("%s is %s, over the %s budget", desc, byteSize(uint64(len(img.Data))), byteSize(uint64(budget)))
+	}
+	return nil, nil
+}
+
+func (l *linter) RenderTable(t Table) (interface{}, error) {
+	defer recovery.Here()()
+	l.item()
+	for _, items := range append([][]interface{}{t.TopRow, t.LeftCol}, t.Cells...) {
+		for _, item := range items {
+			if err := l.essay.visit(l, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,29 @@
+package essay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintTodoCallouts(t *testing.T) {
+	for _, mode := range []TodoMode{TodoIgnore, TodoReport} {
+		e, err := New(Config{Title: "Lint", Backend: LintBackend, Todo: mode})
+		require.NoError(t, err)
+		e.Section("Draft", func(doc Document) {
+			doc.Callout(TodoCallout, "finish this")
+			doc.Note("TODO: and this")
+		})
+
+		var buf strings.Builder
+		_, err = e.WriteTo(&buf)
+		require.Error(t, err)
+		require.Contains(t, buf.String(), `Draft: text contains "TODO": TODO: and this`)
+		if mode == TodoIgnore {
+			require.Contains(t, buf.String(), "Draft: TODO callout\n")
+		} else {
+			require.NotContains(t, buf.String(), "TODO callout")
+		}
+	}
+}
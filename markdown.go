@@ -0,0 +1,243 @@
+package essay
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const maxMarkdownHeading = 6
+
+var (
+	// markdownEscaper escapes the characters of plain text that
+	// Markdown or inline HTML would interpret.
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		`*`, `\*`,
+		`_`, `\_`,
+		`[`, `\[`,
+		`]`, `\]`,
+		`<`, `\<`,
+		`>`, `\>`,
+		`#`, `\#`,
+		`&`, `\&`,
+		`~`, `\~`,
+	)
+
+	// markdownBlock matches the text that would begin a list or
+	// a thematic break.
+	markdownBlock = regexp.MustCompile(`^([-+]|\d+[.)])`)
+)
+
+type (
+	// markdown writes the essay as Markdown, with images and
+	// tables in the forms that GitHub renders.
+	markdown struct {
+		essay *Essay
+		quote int
+	}
+)
+
+func writeMarkdown(e *Essay) error {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	md := &markdown{essay: e}
+	if err := e.walkNode(md, sectionNode, e.config.Title, e.contents()); err != nil {
+		return err
+	}
+	for _, para := range e.provenance.markdown() {
+		if err := md.paragraph(para); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (md *markdown) enter(kind nodeKind, name string) error {
+	switch kind {
+	case sectionNode:
+		level := min(md.essay.Depth(), maxMarkdownHeading)
+		return md.paragraph(strings.Repeat("#", level) + " " + name)
+	case calloutNode:
+		md.quote++
+		return md.paragraph("**" + CalloutKind(name).Title() + "**")
+	case tabNode:
+		return md.paragraph("**" + name + "**")
+	case displayNode:
+		if name != "" {
+			return md.paragraph("**&lt;" + name + "&gt;**")
+		}
+	}
+	return nil
+}
+
+func (md *markdown) leave(kind nodeKind) error {
+	if kind == calloutNode {
+		md.quote--
+		if md.quote == 0 {
+			_, err := io.WriteString(md.essay.out, "\n")
+			return err
+		}
+	}
+	return nil
+}
+
+func (md *markdown) text(s string) error {
+	return md.paragraph(markdownEscape(flowText(s)))
+}
+
+// markdownEscape returns plain text as literal Markdown.
+func markdownEscape(s string) string {
+	s = markdownEscaper.Replace(s)
+	if loc := markdownBlock.FindStringIndex(s); loc != nil {
+		s = s[:loc[1]-1] + `\` + s[loc[1]-1:]
+	}
+	return s
+}
+
+func (md *markdown) html(h template.HTML) error {
+	return md.paragraph(string(h))
+}
+
+func (md *markdown) Theme() Theme {
+	return md.essay.theme
+}
+
+func (md *markdown) PixelRatio() float64 {
+	return md.essay.PixelRatio()
+}
+
+// RenderImage writes an HTML image, since Markdown images have no
+// size.
+func (md *markdown) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	width, height := md.essay.displaySize(img.Bounds)
+	if _, err := fmt.Fprintf(md.essay.out, "%s<img alt=\"%s\" width=\"%d\" height=\"%d\" src=\"",
+		md.prefix(), html.EscapeString(img.Alt), width, height); err != nil {
+		return nil, err
+	}
+	if md.essay.config.ExternalAssets {
+		src, err := md.essay.asset(img)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(md.essay.out, src); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := fmt.Fprintf(md.essay.out, "data:image/%s;base64,", img.Kind); err != nil {
+			return nil, err
+		}
+		if _, err := md.essay.base64(img.Data); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := io.WriteString(md.essay.out, "\">"+md.separator()); err != nil {
+		return nil, err
+	}
+	if img.Caption != "" {
+		if err := md.paragraph("*" + img.Caption + "*"); err != nil {
+			return nil, err
+		}
+	}
+	return nil, md.paragraph(img.Description)
+}
+
+// RenderTable writes a pipe table.  Markdown tables have one line
+// per row, so the paragraphs of each cell are joined by line
+// breaks.
+func (md *markdown) RenderTable(t Table) (interface{}, error) {
+	defer recovery.Here()()
+	cell := func(item interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := md.essay.capture(&buf, func() error {
+			return md.essay.visit(&markdown{essay: md.essay}, item)
+		}); err != nil {
+			return "", err
+		}
+		paras := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+		for i, para := range paras {
+			paras[i] = strings.ReplaceAll(strings.ReplaceAll(para, "\n", " "), "|", "\\|")
+		}
+		return strings.Join(paras, "<br>"), nil
+	}
+	row := func(left interface{}, items []interface{}) (string, error) {
+		var cells []string
+		if t.LeftCol != nil {
+			c, err := cell(left)
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, c)
+		}
+		for _, item := range items {
+			c, err := cell(item)
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, c)
+		}
+		return "| " + strings.Join(cells, " | ") + " |", nil
+	}
+
+	var lines []string
+	var columns int
+	for _, items := range t.Cells {
+		columns = max(columns, len(items))
+	}
+	if t.LeftCol != nil {
+		columns++
+	}
+
+	// Markdown requires a header row, which is empty for tables
+	// without a TopRow.
+	header := "|" + strings.Repeat("  |", columns)
+	if t.TopRow != nil {
+		var err error
+		if header, err = row("", t.TopRow); err != nil {
+			return nil, err
+		}
+	}
+	lines = append(lines, header, "|"+strings.Repeat(" --- |", columns))
+
+	for i, items := range t.Cells {
+		var left interface{}
+		if i < len(t.LeftCol) {
+			left = t.LeftCol[i]
+		}
+		line, err := row(left, items)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return nil, md.paragraph(strings.Join(lines, "\n"))
+}
+
+func (md *markdown) prefix() string {
+	return strings.Repeat("> ", md.quote)
+}
+
+// separator ends a block, continuing the quote inside callouts.
+func (md *markdown) separator() string {
+	return "\n" + strings.TrimSpace(md.prefix()) + "\n"
+}
+
+// paragraph writes one block, quoted inside callouts.
+func (md *markdown) paragraph(s string) error {
+	if s == "" {
+		return nil
+	}
+	prefix := md.prefix()
+	_, err := io.WriteString(md.essay.out, prefix+strings.ReplaceAll(s, "\n", "\n"+prefix)+md.separator())
+	return err
+}
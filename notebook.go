@@ -0,0 +1,281 @@
+package essay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const (
+	notebookFormat      = 4
+	notebookFormatMinor = 5
+)
+
+type (
+	// notebook writes the essay as a Jupyter notebook: text
+	// becomes Markdown cells, while images and tables become
+	// the display outputs of empty code cells.
+	notebook struct {
+		essay    *Essay
+		markdown []string
+		quote    int
+		quoted   int
+		cells    int
+	}
+
+	notebookCell struct {
+		ID       string   `json:"id"`
+		CellType string   `json:"cell_type"`
+		Metadata struct{} `json:"metadata"`
+		Source   string   `json:"source"`
+	}
+
+	// codeCell adds the fields required of code cells; the
+	// execution count is always null.
+	codeCell struct {
+		notebookCell
+		ExecutionCount *int             `json:"execution_count"`
+		Outputs        []notebookOutput `json:"outputs"`
+	}
+
+	notebookOutput struct {
+		OutputType string                 `json:"output_type"`
+		Data       map[string]interface{} `json:"data"`
+		Metadata   map[string]interface{} `json:"metadata"`
+	}
+)
+
+func writeNotebook(e *Essay) (err error) {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	nb := &notebook{essay: e}
+
+	if _, err = io.WriteString(e.out, "{\n \"cells\": [\n"); err != nil {
+		return err
+	}
+	if err = e.walkNode(nb, sectionNode, e.config.Title, e.contents()); err != nil {
+		return err
+	}
+	if err = nb.footer(); err != nil {
+		return err
+	}
+	if err = nb.flush(); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(map[string]interface{}{
+		"language_info": map[string]string{
+			"name": "go",
+		},
+		"essay": e.provenance,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.out, "\n ],\n \"metadata\": %s,\n \"nbformat\": %d,\n \"nbformat_minor\": %d\n}\n",
+		meta, notebookFormat, notebookFormatMinor)
+	return err
+}
+
+func (nb *notebook) enter(kind nodeKind, name string) error {
+	switch kind {
+	case sectionNode:
+		if err := nb.flush(); err != nil {
+			return err
+		}
+		nb.paragraph(strings.Repeat("#", nb.essay.Depth()) + " " + name)
+	case calloutNode:
+		// A callout continues only the quotes enclosing it.
+		nb.quoted = min(nb.quoted, nb.quote)
+		nb.quote++
+		nb.paragraph("**" + CalloutKind(name).Title() + "**")
+	case tabNode:
+		nb.paragraph("**" + name + "**")
+	case displayNode:
+		if name != "" {
+			nb.paragraph("**&lt;" + name + "&gt;**")
+		}
+	}
+	return nil
+}
+
+func (nb *notebook) leave(kind nodeKind) error {
+	if kind == calloutNode {
+		nb.quote--
+	}
+	return nil
+}
+
+func (nb *notebook) text(s string) error {
+	nb.paragraph(markdownEscape(flowText(s)))
+	return nil
+}
+
+func (nb *notebook) html(h template.HTML) error {
+	nb.paragraph(string(h))
+	return nil
+}
+
+func (nb *notebook) Theme() Theme {
+	return nb.essay.theme
+}
+
+func (nb *notebook) PixelRatio() float64 {
+	return nb.essay.PixelRatio()
+}
+
+func (nb *notebook) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	width, height := nb.essay.displaySize(img.Bounds)
+	mime := "image/" + string(img.Kind)
+	var data interface{} = base64.StdEncoding.EncodeToString(img.Data)
+	if img.Kind == SVG {
+		mime = "image/svg+xml"
+		data = string(img.Data)
+	}
+	plain := fmt.Sprintf("<%s image %dx%d>", img.Kind, img.Bounds.Dx(), img.Bounds.Dy())
+	if img.Alt != "" {
+		plain = img.Alt
+	}
+	if err := nb.output(notebookOutput{
+		OutputType: "display_data",
+		Data: map[string]interface{}{
+			mime:         data,
+			"text/plain": plain,
+		},
+		Metadata: map[string]interface{}{
+			mime: map[string]int{
+				"width":  width,
+				"height": height,
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if img.Caption != "" {
+		nb.paragraph("*" + img.Caption + "*")
+	}
+	if img.Description != "" {
+		nb.paragraph(img.Description)
+	}
+	return nil, nil
+}
+
+func (nb *notebook) RenderTable(t Table) (interface{}, error) {
+	defer recovery.Here()()
+	var buf bytes.Buffer
+	if err := nb.essay.capture(&buf, func() error {
+		_, err := nb.essay.RenderTable(t)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return nil, nb.output(notebookOutput{
+		OutputType: "display_data",
+		Data: map[string]interface{}{
+			"text/html":  buf.String(),
+			"text/plain": fmt.Sprintf("<table %d rows>", len(t.Cells)),
+		},
+		Metadata: map[string]interface{}{},
+	})
+}
+
+func (nb *notebook) footer() error {
+	if err := nb.flush(); err != nil {
+		return err
+	}
+	nb.markdown = append(nb.markdown, nb.essay.provenance.markdown()...)
+	return nil
+}
+
+// paragraph adds a paragraph, quoted inside callouts.  Paragraphs
+// of one quote are separated by a quoted blank line, since a blank
+// line would end the quote.
+func (nb *notebook) paragraph(s string) {
+	quoted := nb.quoted
+	nb.quoted = nb.quote
+	if nb.quote == 0 {
+		nb.markdown = append(nb.markdown, s)
+		return
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = quoteLine(nb.quote, line)
+	}
+	s = strings.Join(lines, "\n")
+	if last := len(nb.markdown) - 1; last >= 0 && quoted > 0 {
+		nb.markdown[last] += "\n" + quoteLine(min(quoted, nb.quote), "") + "\n" + s
+		return
+	}
+	nb.markdown = append(nb.markdown, s)
+}
+
+func quoteLine(depth int, line string) string {
+	if line == "" {
+		return strings.TrimSpace(strings.Repeat("> ", depth))
+	}
+	return strings.Repeat("> ", depth) + line
+}
+
+// flush emits the pending Markdown as one cell.
+func (nb *notebook) flush() error {
+	if len(nb.markdown) == 0 {
+		return nil
+	}
+	source := strings.Join(nb.markdown, "\n\n")
+	nb.markdown = nil
+	nb.quoted = 0
+	return nb.cell(notebookCell{
+		ID:       nb.nextID(),
+		CellType: "markdown",
+		Source:   source,
+	})
+}
+
+func (nb *notebook) output(out notebookOutput) error {
+	if err := nb.flush(); err != nil {
+		return err
+	}
+	return nb.cell(codeCell{
+		notebookCell: notebookCell{
+			ID:       nb.nextID(),
+			CellType: "code",
+		},
+		Outputs: []notebookOutput{out},
+	})
+}
+
+func (nb *notebook) nextID() string {
+	nb.cells++
+	return fmt.Sprint("cell-", nb.cells)
+}
+
+// cell writes one cell; cells are written as they complete, so
+// the notebook streams like the HTML essay.
+func (nb *notebook) cell(c interface{}) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if nb.cells != 1 {
+		if _, err := io.WriteString(nb.essay.out, ",\n"); err != nil {
+			return err
+		}
+	}
+	_, err = nb.essay.out.Write(data)
+	return err
+}
+
+// flowText joins the lines of a note, which are often indented
+// Go string literals, into one Markdown paragraph.
+func flowText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
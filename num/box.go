@@ -0,0 +1,323 @@
+package num
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	// defaultBoxWidth is the width of a box or violin, as a
+	// fraction of the distance between nominal values.
+	defaultBoxWidth = 0.6
+
+	// whiskerFactor extends whiskers to the furthest value within
+	// this many interquartile ranges of the box.
+	whiskerFactor = 1.5
+)
+
+var defaultOutlierRadius = vg.Points(2)
+
+type (
+	// Sample is a named sample of values, drawn at the nominal X
+	// value of the same name.
+	Sample struct {
+		Name   string
+		Values []float64
+
+		// Weights are the weights of the values; nil means
+		// one each.
+		Weights []float64
+
+		// Density replaces the kernel density estimate of a
+		// violin, for example with a continuous.Digest.
+		Density Densitier
+	}
+
+	// Densitier is a probability density.
+	Densitier interface {
+		ProbDensity(value float64) float64
+	}
+
+	// BoxBuilder draws a box plot of each sample: the box spans
+	// the quartiles, and the whiskers reach the furthest values
+	// within 1.5 interquartile ranges, beyond which values are
+	// drawn as outliers.  On a log-scale Y axis the whiskers are
+	// computed in the logarithm.
+	BoxBuilder struct {
+		samples []Sample
+		width   float64
+		color   color.Color
+		name    string
+	}
+
+	boxStats struct {
+		pos                 float64
+		low, q1, median, q3 float64
+		high                float64
+		outliers            []float64
+		minValue, maxValue  float64
+	}
+
+	boxes struct {
+		stats []boxStats
+		width float64
+		fill  color.Color
+		line  draw.LineStyle
+	}
+
+	// valueScale transforms values into the space in which
+	// statistics are computed, the logarithm for log-scale axes.
+	valueScale struct {
+		log bool
+	}
+
+	weighted struct {
+		value, weight float64
+	}
+
+	// fillThumbnail draws a filled and outlined legend entry.
+	fillThumbnail struct {
+		fill color.Color
+		line draw.LineStyle
+	}
+)
+
+func Box(samples ...Sample) BoxBuilder {
+	return BoxBuilder{
+		samples: samples,
+		width:   defaultBoxWidth,
+	}
+}
+
+// Width sets the width of each box, as a fraction of the distance
+// between nominal values.
+func (b BoxBuilder) Width(fraction float64) BoxBuilder {
+	b.width = fraction
+	return b
+}
+
+// Color fills the boxes.
+func (b BoxBuilder) Color(color color.Color) BoxBuilder {
+	b.color = color
+	return b
+}
+
+func (b BoxBuilder) Name(name string) BoxBuilder {
+	b.name = name
+	return b
+}
+
+func (b BoxBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	pos, err := samplePositions(builder, b.samples)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	scale := valueScale{log: builder.YAxis.logscale}
+	bx := &boxes{
+		width: b.width,
+		fill:  b.color,
+		line: draw.LineStyle{
+			Color: builder.foreground(nil),
+			Width: builder.lineWidth(0),
+		},
+	}
+	for i, s := range b.samples {
+		ws, err := scale.sorted(s)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bx.stats = append(bx.stats, boxSummary(pos[i], ws, scale))
+	}
+	builder.Plot.Add(bx)
+	return bx, bx, nil, nil
+}
+
+func (b BoxBuilder) namePlot() string {
+	return b.name
+}
+
+// samplePositions places each sample at the nominal X value of its
+// name, or in order, naming the nominal X values, when the X axis
+// is not nominal.
+func samplePositions(builder Builder, samples []Sample) ([]float64, error) {
+	pos := make([]float64, len(samples))
+	if builder.XAxis.nominal == nil {
+		var names []string
+		for i, s := range samples {
+			pos[i] = float64(i)
+			names = append(names, s.Name)
+		}
+		builder.Plot.NominalX(names...)
+		return pos, nil
+	}
+	index := map[string]int{}
+	for i, name := range builder.XAxis.nominal {
+		index[name] = i
+	}
+	for i, s := range samples {
+		x, ok := index[s.Name]
+		if !ok {
+			return nil, fmt.Errorf("num: sample %q is not a nominal X value", s.Name)
+		}
+		pos[i] = float64(x)
+	}
+	return pos, nil
+}
+
+func (vs valueScale) to(v float64) float64 {
+	if vs.log {
+		return math.Log(v)
+	}
+	return v
+}
+
+func (vs valueScale) from(v float64) float64 {
+	if vs.log {
+		return math.Exp(v)
+	}
+	return v
+}
+
+// sorted returns the sample in the scale's space, sorted by value.
+func (vs valueScale) sorted(s Sample) ([]weighted, error) {
+	if s.Weights != nil && len(s.Weights) != len(s.Values) {
+		return nil, fmt.Errorf("num: sample %q weights differ in length from its values", s.Name)
+	}
+	ws := make([]weighted, 0, len(s.Values))
+	for i, v := range s.Values {
+		if vs.log && v <= 0 {
+			return nil, fmt.Errorf("num: sample %q has non-positive values on a log scale", s.Name)
+		}
+		w := 1.0
+		if s.Weights != nil {
+			w = s.Weights[i]
+		}
+		ws = append(ws, weighted{value: vs.to(v), weight: w})
+	}
+	if len(ws) == 0 {
+		return nil, fmt.Errorf("num: sample %q is empty", s.Name)
+	}
+	sort.Slice(ws, func(i, j int) bool {
+		return ws[i].value < ws[j].value
+	})
+	return ws, nil
+}
+
+// quantile returns the least value with at least the fraction q of
+// the total weight at or below it.
+func quantile(ws []weighted, q float64) float64 {
+	total := 0.0
+	for _, w := range ws {
+		total += w.weight
+	}
+	cum := 0.0
+	for _, w := range ws {
+		cum += w.weight
+		if cum >= q*total {
+			return w.value
+		}
+	}
+	return ws[len(ws)-1].value
+}
+
+func boxSummary(pos float64, ws []weighted, scale valueScale) boxStats {
+	q1, median, q3 := quantile(ws, 0.25), quantile(ws, 0.5), quantile(ws, 0.75)
+	lowFence := q1 - whiskerFactor*(q3-q1)
+	highFence := q3 + whiskerFactor*(q3-q1)
+
+	st := boxStats{
+		pos:      pos,
+		q1:       scale.from(q1),
+		median:   scale.from(median),
+		q3:       scale.from(q3),
+		low:      scale.from(q1),
+		high:     scale.from(q3),
+		minValue: scale.from(ws[0].value),
+		maxValue: scale.from(ws[len(ws)-1].value),
+	}
+	for _, w := range ws {
+		v := scale.from(w.value)
+		switch {
+		case w.value < lowFence || w.value > highFence:
+			st.outliers = append(st.outliers, v)
+		case v < st.low:
+			st.low = v
+		case v > st.high:
+			st.high = v
+		}
+	}
+	return st
+}
+
+func (bx *boxes) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	for _, st := range bx.stats {
+		x0 := trX(st.pos - bx.width/2)
+		x1 := trX(st.pos + bx.width/2)
+		xm := trX(st.pos)
+		q1, q3 := trY(st.q1), trY(st.q3)
+
+		box := []vg.Point{{X: x0, Y: q1}, {X: x1, Y: q1}, {X: x1, Y: q3}, {X: x0, Y: q3}}
+		if bx.fill != nil {
+			c.FillPolygon(bx.fill, c.ClipPolygonXY(box))
+		}
+		c.StrokeLines(bx.line, c.ClipLinesXY(append(box, box[0]))...)
+		c.StrokeLines(bx.line, c.ClipLinesXY(
+			[]vg.Point{{X: x0, Y: trY(st.median)}, {X: x1, Y: trY(st.median)}},
+			[]vg.Point{{X: xm, Y: q3}, {X: xm, Y: trY(st.high)}},
+			[]vg.Point{{X: xm, Y: q1}, {X: xm, Y: trY(st.low)}},
+		)...)
+
+		glyph := draw.GlyphStyle{
+			Color:  bx.line.Color,
+			Radius: defaultOutlierRadius,
+			Shape:  draw.RingGlyph{},
+		}
+		for _, v := range st.outliers {
+			pt := vg.Point{X: xm, Y: trY(v)}
+			if c.Contains(pt) {
+				c.DrawGlyph(glyph, pt)
+			}
+		}
+	}
+}
+
+func (bx *boxes) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return statsRange(bx.stats)
+}
+
+func (bx *boxes) Thumbnail(c *draw.Canvas) {
+	fillThumbnail{bx.fill, bx.line}.Thumbnail(c)
+}
+
+// statsRange spans the nominal positions and the sample values.
+func statsRange(stats []boxStats) (xmin, xmax, ymin, ymax float64) {
+	xmin, ymin = math.Inf(+1), math.Inf(+1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	for _, st := range stats {
+		xmin = math.Min(xmin, st.pos-0.5)
+		xmax = math.Max(xmax, st.pos+0.5)
+		ymin = math.Min(ymin, st.minValue)
+		ymax = math.Max(ymax, st.maxValue)
+	}
+	return
+}
+
+func (t fillThumbnail) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+	}
+	if t.fill != nil {
+		c.FillPolygon(t.fill, pts)
+	}
+	c.StrokeLines(t.line, append(pts, pts[0]))
+}
@@ -0,0 +1,188 @@
+package num
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmacd/essay"
+	"github.com/jmacd/essay/lib/gonum/loghist"
+	"gonum.org/v1/plot/plotter"
+)
+
+const (
+	// maxFallbackRows limits the rows listed for each series in
+	// the data table fallback.
+	maxFallbackRows = 100
+
+	functionFallbackRows = 11
+)
+
+type (
+	// tabler is implemented by plotters that can list their
+	// data in the data table fallback, as rows of x and y.
+	// Heatmaps, box and violin plots and stacked areas are not
+	// tablers, and are named in the alt text as omitted.
+	tabler interface {
+		fallbackRows(Builder) [][]interface{}
+	}
+)
+
+// defaultAlt describes the plot by its title, axis labels and
+// plotter names.
+func (b Builder) defaultAlt() string {
+	alt := "Plot"
+	if title := b.Plot.Title.Text; title != "" {
+		alt += fmt.Sprintf(" of %q", title)
+	}
+	if x, y := b.Plot.X.Label.Text, b.Plot.Y.Label.Text; x != "" || y != "" {
+		alt += fmt.Sprintf(" with %s versus %s", labelOr(y, "an unlabeled axis"), labelOr(x, "an unlabeled axis"))
+	}
+
+	var names []string
+	for _, p := range b.Plotters {
+		if name := p.namePlot(); name != "" {
+			names = append(names, name)
+		}
+	}
+	switch {
+	case len(names) != 0:
+		alt += ", showing " + joinNames(names)
+	case len(b.Plotters) == 1:
+		alt += ", showing 1 series"
+	case len(b.Plotters) > 1:
+		alt += fmt.Sprintf(", showing %d series", len(b.Plotters))
+	}
+	return alt
+}
+
+func labelOr(label, def string) string {
+	if label == "" {
+		return def
+	}
+	return label
+}
+
+// joinNames joins names as in "a, b and c".
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// fallback tabulates the plotted series.  It is called after
+// setupPlot, so that functions are sampled over the plot range.
+func (b Builder) fallback() *essay.Table {
+	table := &essay.Table{
+		TopRow: []interface{}{"Series", labelOr(b.Plot.X.Label.Text, "x"), labelOr(b.Plot.Y.Label.Text, "y")},
+	}
+	for i, p := range b.Plotters {
+		t, ok := p.(tabler)
+		if !ok {
+			continue
+		}
+		name := p.namePlot()
+		if name == "" {
+			name = fmt.Sprint("series ", i+1)
+		}
+		rows := t.fallbackRows(b)
+		if len(rows) > maxFallbackRows {
+			omitted := fmt.Sprintf("%d more rows", len(rows)-maxFallbackRows)
+			rows = append(rows[:maxFallbackRows], []interface{}{omitted, ""})
+		}
+		for _, row := range rows {
+			table.Cells = append(table.Cells, append([]interface{}{name}, row...))
+		}
+	}
+	return table
+}
+
+// untabled names the plotters that the data table omits.
+func (b Builder) untabled() []string {
+	var names []string
+	for i, p := range b.Plotters {
+		if _, ok := p.(tabler); ok {
+			continue
+		}
+		name := p.namePlot()
+		if name == "" {
+			name = fmt.Sprint("series ", i+1)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func formatValue(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
+
+func xyRows(data plotter.XYer) [][]interface{} {
+	var rows [][]interface{}
+	for i := 0; i < data.Len(); i++ {
+		x, y := data.XY(i)
+		rows = append(rows, []interface{}{formatValue(x), formatValue(y)})
+	}
+	return rows
+}
+
+func (l LBuilder) fallbackRows(Builder) [][]interface{} {
+	return xyRows(l.data)
+}
+
+func (s SBuilder) fallbackRows(Builder) [][]interface{} {
+	return xyRows(s.data)
+}
+
+func (f FBuilder) fallbackRows(b Builder) [][]interface{} {
+	var rows [][]interface{}
+	min, max := b.Plot.X.Min, b.Plot.X.Max
+	for i := 0; i < functionFallbackRows; i++ {
+		x := min + (max-min)*float64(i)/(functionFallbackRows-1)
+		rows = append(rows, []interface{}{formatValue(x), formatValue(f.F(x))})
+	}
+	return rows
+}
+
+func (h HBuilder) fallbackRows(Builder) [][]interface{} {
+	var rows [][]interface{}
+	add := func(prefix string, bins []loghist.HistogramBin) {
+		for _, bin := range bins {
+			rows = append(rows, []interface{}{
+				fmt.Sprintf("%s[%s, %s)", prefix, formatValue(bin.Min), formatValue(bin.Max)),
+				formatValue(bin.Weight),
+			})
+		}
+	}
+	if h.stack == nil {
+		add("", h.bins)
+	}
+	for _, c := range h.stack {
+		add(c.name+" ", c.bins)
+	}
+	return rows
+}
+
+func (n NomBuilder) fallbackRows(Builder) [][]interface{} {
+	var rows [][]interface{}
+	for i := 0; i < n.valuer.Len(); i++ {
+		rows = append(rows, []interface{}{fmt.Sprint(i), formatValue(n.valuer.Value(i))})
+	}
+	return rows
+}
+
+func (e ECDFBuilder) fallbackRows(Builder) [][]interface{} {
+	steps, _ := e.steps()
+	return xyRows(steps)
+}
+
+func (q QQBuilder) fallbackRows(Builder) [][]interface{} {
+	return xyRows(q.points())
+}
+
+func (k KDEBuilder) fallbackRows(b Builder) [][]interface{} {
+	if k.samples < 2 {
+		return nil
+	}
+	return xyRows(k.curve(b.XAxis.logscale, functionFallbackRows))
+}
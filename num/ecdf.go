@@ -0,0 +1,169 @@
+package num
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// defaultBandAlpha is the opacity of confidence bands, relative to
+// their line.
+const defaultBandAlpha = 0.25
+
+type (
+	// ECDFBuilder draws the empirical distribution of weighted
+	// data, a plotter.XYer of value and weight, as a step line.
+	ECDFBuilder struct {
+		data     plotter.XYer
+		survival bool
+		level    float64
+		width    vg.Length
+		color    color.Color
+		name     string
+	}
+)
+
+// ECDF draws the fraction of the weight at or below each value.
+func ECDF(data plotter.XYer) ECDFBuilder {
+	return ECDFBuilder{
+		data: data,
+	}
+}
+
+// Survival draws the fraction of the weight above each value, the
+// complementary CDF, which shows heavy tails as straight lines on
+// log-log axes.  The step to zero at the largest value is omitted.
+func Survival(data plotter.XYer) ECDFBuilder {
+	return ECDFBuilder{
+		data:     data,
+		survival: true,
+	}
+}
+
+// Band shades the Dvoretzky–Kiefer–Wolfowitz confidence band at the
+// given level, such as 0.95, using the effective size of the
+// weighted sample.
+func (e ECDFBuilder) Band(level float64) ECDFBuilder {
+	e.level = level
+	return e
+}
+
+func (e ECDFBuilder) Width(points float64) ECDFBuilder {
+	e.width = vg.Points(points)
+	return e
+}
+
+func (e ECDFBuilder) Color(color color.Color) ECDFBuilder {
+	e.color = color
+	return e
+}
+
+func (e ECDFBuilder) Name(name string) ECDFBuilder {
+	e.name = name
+	return e
+}
+
+func (e ECDFBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	steps, n := e.steps()
+	lineColor := builder.foreground(e.color)
+
+	if e.level != 0 && len(steps) != 0 {
+		eps := math.Sqrt(math.Log(2/(1-e.level)) / (2 * n))
+		var upper, lower plotter.XYs
+		for i, s := range steps {
+			hi := math.Min(1, s.Y+eps)
+			lo := math.Max(0, s.Y-eps)
+			upper = append(upper, plotter.XY{X: s.X, Y: hi})
+			lower = append(lower, plotter.XY{X: s.X, Y: lo})
+			if i+1 < len(steps) {
+				upper = append(upper, plotter.XY{X: steps[i+1].X, Y: hi})
+				lower = append(lower, plotter.XY{X: steps[i+1].X, Y: lo})
+			}
+		}
+		for i := len(lower) - 1; i >= 0; i-- {
+			upper = append(upper, lower[i])
+		}
+		band, err := plotter.NewPolygon(upper)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		band.LineStyle.Width = 0
+		band.Color = withAlpha(lineColor, defaultBandAlpha)
+		builder.Plot.Add(band)
+	}
+
+	line, err := plotter.NewLine(steps)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	line.StepStyle = plotter.PostStep
+	line.LineStyle.Width = builder.lineWidth(e.width)
+	line.LineStyle.Color = lineColor
+	builder.Plot.Add(line)
+	return line, line, nil, nil
+}
+
+func (e ECDFBuilder) namePlot() string {
+	return e.name
+}
+
+// steps returns a point at each distinct value, with the fraction
+// of the weight at or below it, or above it for survival, and the
+// effective size of the sample.  The last point extends the last
+// step to the largest value.
+func (e ECDFBuilder) steps() (plotter.XYs, float64) {
+	ws := sortedXYs(e.data)
+	var total, sumWSq float64
+	for _, w := range ws {
+		total += w.weight
+		sumWSq += w.weight * w.weight
+	}
+
+	var steps plotter.XYs
+	cum := 0.0
+	for i, w := range ws {
+		cum += w.weight
+		if i+1 < len(ws) && ws[i+1].value == w.value {
+			continue
+		}
+		f := cum / total
+		if e.survival {
+			f = 1 - f
+		}
+		if e.survival && i == len(ws)-1 {
+			if len(steps) != 0 {
+				steps = append(steps, plotter.XY{X: w.value, Y: steps[len(steps)-1].Y})
+			}
+			break
+		}
+		steps = append(steps, plotter.XY{X: w.value, Y: f})
+	}
+	if total == 0 {
+		return nil, 0
+	}
+	return steps, total * total / sumWSq
+}
+
+// sortedXYs returns data of value and weight, sorted by value.
+func sortedXYs(data plotter.XYer) []weighted {
+	ws := make([]weighted, data.Len())
+	for i := range ws {
+		ws[i].value, ws[i].weight = data.XY(i)
+	}
+	sort.Slice(ws, func(i, j int) bool {
+		return ws[i].value < ws[j].value
+	})
+	return ws
+}
+
+func withAlpha(c color.Color, alpha float64) color.Color {
+	r, g, b, a := c.RGBA()
+	scale := func(v uint32) uint16 {
+		return uint16(float64(v) * alpha)
+	}
+	return color.RGBA64{R: scale(r), G: scale(g), B: scale(b), A: scale(a)}
+}
@@ -20,9 +20,7 @@ type (
 
 func Function(F func(float64) float64) FBuilder {
 	return FBuilder{
-		F:     F,
-		width: defaultLineWidth,
-		color: defaultLineColor,
+		F: F,
 	}
 }
 
@@ -48,8 +46,8 @@ func (f FBuilder) Samples(samples int) FBuilder {
 
 func (f FBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
 	fun := plotter.NewFunction(f.F)
-	fun.LineStyle.Width = f.width
-	fun.LineStyle.Color = f.color
+	fun.LineStyle.Width = builder.lineWidth(f.width)
+	fun.LineStyle.Color = builder.foreground(f.color)
 	fun.Samples = f.samples
 	builder.Plot.Add(fun)
 	return fun, nil, nil, nil
@@ -0,0 +1,322 @@
+package num
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"strings"
+
+	"github.com/jmacd/essay"
+	"github.com/jmacd/essay/internal/recovery"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var (
+	defaultGridPadding   = vg.Points(5)
+	defaultLegendSpacing = vg.Points(10)
+)
+
+type (
+	// GridBuilder lays out plots as small multiples, in one image.
+	// Each panel is titled by its own Builder's title.
+	GridBuilder struct {
+		Panels  []Builder
+		Columns int
+
+		// Width and Height are the size of the image; zero
+		// means the size of the first panel times the number
+		// of columns and rows.
+		Width  int
+		Height int
+
+		sharedX, sharedY bool
+		legend           bool
+		theme            *essay.Theme
+		ratio            float64
+		alt              string
+	}
+
+	// unlabeledTicks hides the tick labels of inner panels,
+	// whose axes are labeled along the outer edge of the grid.
+	unlabeledTicks struct {
+		plot.Ticker
+	}
+)
+
+// Grid lays out panels in rows of the given number of columns.
+func Grid(columns int, panels ...Builder) GridBuilder {
+	return GridBuilder{
+		Panels:  panels,
+		Columns: columns,
+	}
+}
+
+// Facet lays out n panels built by f, in rows of the given number
+// of columns.
+func Facet(n, columns int, f func(i int) Builder) GridBuilder {
+	g := Grid(columns)
+	for i := 0; i < n; i++ {
+		g = g.Add(f(i))
+	}
+	return g
+}
+
+func (g GridBuilder) Add(panels ...Builder) GridBuilder {
+	g.Panels = append(g.Panels, panels...)
+	return g
+}
+
+func (g GridBuilder) Size(w, h int) GridBuilder {
+	g.Width = w
+	g.Height = h
+	return g
+}
+
+// ShareX gives every panel the same X range and scale, and labels
+// the X axis on the bottom row only.
+func (g GridBuilder) ShareX() GridBuilder {
+	g.sharedX = true
+	return g
+}
+
+// ShareY gives every panel the same Y range and scale, and labels
+// the Y axis on the left column only.
+func (g GridBuilder) ShareY() GridBuilder {
+	g.sharedY = true
+	return g
+}
+
+// Legend draws one legend for the grid, beside the panels, with an
+// entry for each distinct plotter name.
+func (g GridBuilder) Legend() GridBuilder {
+	g.legend = true
+	return g
+}
+
+func (g GridBuilder) Theme(t essay.Theme) GridBuilder {
+	g.theme = &t
+	return g
+}
+
+func (g GridBuilder) PixelRatio(ratio float64) GridBuilder {
+	g.ratio = ratio
+	return g
+}
+
+func (g GridBuilder) Alt(text string) GridBuilder {
+	g.alt = text
+	return g
+}
+
+func (g GridBuilder) Render(builtin essay.Builtin) (interface{}, error) {
+	defer recovery.Here()()
+	return builtin.RenderImage(g.Frame(builtin))
+}
+
+// Frame draws the grid in the theme and at the pixel ratio of the
+// essay, as a frame of an essay.Animation.
+func (g GridBuilder) Frame(builtin essay.Builtin) essay.EncodedImage {
+	if g.theme == nil {
+		g = g.Theme(builtin.Theme())
+	}
+	if g.ratio == 0 {
+		g.ratio = builtin.PixelRatio()
+	}
+	return g.Image(essay.PNG)
+}
+
+func (g GridBuilder) rows() int {
+	return (len(g.Panels) + g.Columns - 1) / g.Columns
+}
+
+func (g GridBuilder) size() (int, int) {
+	w, h := g.Width, g.Height
+	if w == 0 && len(g.Panels) != 0 {
+		w = g.Panels[0].Width * g.Columns
+	}
+	if h == 0 && len(g.Panels) != 0 {
+		h = g.Panels[0].Height * g.rows()
+	}
+	return w, h
+}
+
+func (g GridBuilder) defaultAlt() string {
+	var alts []string
+	for _, p := range g.Panels {
+		alts = append(alts, p.defaultAlt())
+	}
+	return fmt.Sprintf("Grid of %d plots: %s", len(g.Panels), strings.Join(alts, "; "))
+}
+
+func (g GridBuilder) Image(kind essay.ImageKind) essay.EncodedImage {
+	defer recovery.Here()()
+	if g.Columns <= 0 {
+		panic("num: grid has no columns")
+	}
+	if len(g.Panels) == 0 {
+		panic("num: grid has no panels")
+	}
+	alt := g.alt
+	if alt == "" {
+		alt = g.defaultAlt()
+	}
+
+	// Set up each panel, gathering the legend entries and the
+	// colorbars, which are drawn beside their panels.
+	var entries []legendEntry
+	bars := make([]*colorBar, len(g.Panels))
+	panels := make([]Builder, len(g.Panels))
+	named := map[string]bool{}
+	plots := make([][]*plot.Plot, g.rows())
+	for i := range plots {
+		plots[i] = make([]*plot.Plot, g.Columns)
+	}
+	for i, panel := range g.Panels {
+		if panel.theme == nil {
+			panel.theme = g.theme
+		}
+		if g.legend {
+			panel.DrawLegend = false
+		}
+		var legend []legendEntry
+		legend, bars[i] = panel.setupPlot()
+		panels[i] = panel
+		for _, e := range legend {
+			if e.name != "" && !named[e.name] {
+				named[e.name] = true
+				entries = append(entries, e)
+			}
+		}
+		plots[i/g.Columns][i%g.Columns] = panel.Plot
+	}
+	g.share(plots)
+
+	width, height := g.size()
+	c := newCanvas(kind, vg.Length(width), vg.Length(height), g.ratio)
+	dc := draw.New(c)
+	theme := g.currentTheme()
+	dc.SetColor(theme.Background)
+	dc.Fill(dc.Rectangle.Path())
+
+	if g.legend && len(entries) != 0 {
+		legend := plot.NewLegend()
+		legend.Top = true
+		for _, e := range entries {
+			legend.Add(e.name, e.thumb)
+		}
+		// Use the text style of the first panel's legend,
+		// which the theme has set.
+		legend.TextStyle = g.Panels[0].Plot.Legend.TextStyle
+		right := legend.Rectangle(dc)
+		legendCanvas := dc
+		legendCanvas.Min.X = dc.Max.X - (right.Max.X - right.Min.X)
+		legendCanvas.Max.Y -= defaultGridPadding
+		legend.Draw(legendCanvas)
+		dc.Max.X = legendCanvas.Min.X - defaultLegendSpacing
+	}
+
+	tiles := draw.Tiles{
+		Rows:      g.rows(),
+		Cols:      g.Columns,
+		PadX:      defaultGridPadding,
+		PadY:      defaultGridPadding,
+		PadTop:    defaultGridPadding,
+		PadBottom: defaultGridPadding,
+		PadLeft:   defaultGridPadding,
+		PadRight:  defaultGridPadding,
+	}
+	canvases := plot.Align(plots, tiles, dc)
+	for j, row := range plots {
+		for i, p := range row {
+			if p == nil {
+				continue
+			}
+			pc := canvases[j][i]
+			if bar := bars[j*g.Columns+i]; bar != nil {
+				pc = panels[j*g.Columns+i].drawColorBar(pc, bar)
+			}
+			p.Draw(pc)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		panic(err)
+	}
+	return essay.EncodedImage{
+		Kind: kind,
+		Bounds: image.Rectangle{
+			Min: image.Point{},
+			Max: image.Pt(width, height),
+		},
+		Data: buf.Bytes(),
+		Alt:  alt,
+	}
+}
+
+func (g GridBuilder) currentTheme() essay.Theme {
+	return Builder{theme: g.theme}.currentTheme()
+}
+
+// share sets the shared axes to the union of the panel ranges,
+// with the scale of the first panel, and hides inner tick labels.
+func (g GridBuilder) share(plots [][]*plot.Plot) {
+	axes := func(shared bool, get func(*plot.Plot) *plot.Axis, inner func(row, col int) bool) {
+		if !shared {
+			return
+		}
+		var first *plot.Axis
+		min, max := math.Inf(+1), math.Inf(-1)
+		for _, row := range plots {
+			for _, p := range row {
+				if p == nil {
+					continue
+				}
+				a := get(p)
+				if first == nil {
+					first = a
+				}
+				min = math.Min(min, a.Min)
+				max = math.Max(max, a.Max)
+			}
+		}
+		if first == nil {
+			return
+		}
+		scale, marker := first.Scale, first.Tick.Marker
+		for j, row := range plots {
+			for i, p := range row {
+				if p == nil {
+					continue
+				}
+				a := get(p)
+				a.Min, a.Max = min, max
+				a.Scale = scale
+				a.Tick.Marker = marker
+				if inner(j, i) {
+					a.Tick.Marker = unlabeledTicks{a.Tick.Marker}
+					a.Label.Text = ""
+				}
+			}
+		}
+	}
+	lastRow := len(plots) - 1
+	axes(g.sharedX, func(p *plot.Plot) *plot.Axis { return &p.X }, func(row, col int) bool {
+		// A panel is on the bottom edge if nothing is below it.
+		return row < lastRow && plots[row+1][col] != nil
+	})
+	axes(g.sharedY, func(p *plot.Plot) *plot.Axis { return &p.Y }, func(row, col int) bool {
+		return col > 0
+	})
+}
+
+func (u unlabeledTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := u.Ticker.Ticks(min, max)
+	for i := range ticks {
+		ticks[i].Label = ""
+	}
+	return ticks
+}
@@ -0,0 +1,348 @@
+package num
+
+import (
+	"errors"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	defaultHeatBins   = 20
+	defaultHeatColors = 64
+)
+
+var (
+	defaultColorBarWidth = vg.Points(60)
+
+	errHeatWeights = errors.New("num: heatmap weights differ in length from the data")
+	errHeatLog     = errors.New("num: heatmap log bins need positive values")
+	errHeatPalette = errors.New("num: heatmap palette has no colors")
+	errHeatBins    = errors.New("num: heatmap needs at least one bin on each axis")
+)
+
+type (
+	// HeatBuilder bins points into a two-dimensional grid and
+	// colors each cell by its total weight, with a colorbar
+	// beside the plot.  Cells of zero weight are not drawn.
+	HeatBuilder struct {
+		data    plotter.XYer
+		weights plotter.Valuer
+		x, y    heatAxis
+		palette palette.Palette
+		name    string
+	}
+
+	heatAxis struct {
+		bins     int
+		log      bool
+		min, max *float64
+	}
+
+	// heatGrid is the binned data: cell (i, j) spans the X
+	// edges i and i+1 and the Y edges j and j+1.
+	heatGrid struct {
+		xedges, yedges []float64
+		cells          [][]float64
+	}
+
+	heatmap struct {
+		heatGrid
+		bar *colorBar
+	}
+
+	// colorBar maps weights from min to max onto the colors.
+	colorBar struct {
+		name     string
+		min, max float64
+		colors   []color.Color
+	}
+
+	// colorBarer is implemented by the value rangers of
+	// plotters that draw a colorbar beside the plot.
+	colorBarer interface {
+		colorBar() *colorBar
+	}
+)
+
+// Heatmap bins data into 20 by 20 cells spanning its range, each
+// point counting once.  Points with a NaN or infinite coordinate
+// are dropped.
+func Heatmap(data plotter.XYer) HeatBuilder {
+	return HeatBuilder{
+		data: data,
+		x:    heatAxis{bins: defaultHeatBins},
+		y:    heatAxis{bins: defaultHeatBins},
+	}
+}
+
+// Weights sets the weight of each point, instead of one.
+func (h HeatBuilder) Weights(weights plotter.Valuer) HeatBuilder {
+	h.weights = weights
+	return h
+}
+
+// Bins sets the number of cells on each axis, which must be at
+// least one.
+func (h HeatBuilder) Bins(x, y int) HeatBuilder {
+	h.x.bins = x
+	h.y.bins = y
+	return h
+}
+
+// LogX spaces the X bins evenly in the logarithm, for use with a
+// log-scale X axis.
+func (h HeatBuilder) LogX() HeatBuilder {
+	h.x.log = true
+	return h
+}
+
+// LogY spaces the Y bins evenly in the logarithm, for use with a
+// log-scale Y axis.
+func (h HeatBuilder) LogY() HeatBuilder {
+	h.y.log = true
+	return h
+}
+
+// XRange sets the range of the X bins; points outside it are
+// dropped.
+func (h HeatBuilder) XRange(min, max float64) HeatBuilder {
+	h.x.min, h.x.max = &min, &max
+	return h
+}
+
+// YRange sets the range of the Y bins; points outside it are
+// dropped.
+func (h HeatBuilder) YRange(min, max float64) HeatBuilder {
+	h.y.min, h.y.max = &min, &max
+	return h
+}
+
+// Palette colors the cells, from the least weight to the greatest.
+// The default is the extended black body palette.
+func (h HeatBuilder) Palette(p palette.Palette) HeatBuilder {
+	h.palette = p
+	return h
+}
+
+// Name labels the colorbar.
+func (h HeatBuilder) Name(name string) HeatBuilder {
+	h.name = name
+	return h
+}
+
+func (h HeatBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	colors := moreland.ExtendedBlackBody().Palette(defaultHeatColors).Colors()
+	if h.palette != nil {
+		colors = h.palette.Colors()
+	}
+	if len(colors) == 0 {
+		return nil, nil, nil, errHeatPalette
+	}
+	grid, err := h.grid()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hm := &heatmap{heatGrid: grid, bar: grid.colorBar(h.name, colors)}
+	builder.Plot.Add(hm)
+	return nil, hm, nil, nil
+}
+
+func (h HeatBuilder) namePlot() string {
+	return h.name
+}
+
+// colorBar spans the weights of the cells.
+func (g heatGrid) colorBar(name string, colors []color.Color) *colorBar {
+	bar := &colorBar{name: name, colors: colors}
+	for _, col := range g.cells {
+		for _, w := range col {
+			bar.min = math.Min(bar.min, w)
+			bar.max = math.Max(bar.max, w)
+		}
+	}
+	if bar.min == bar.max {
+		bar.max = bar.min + 1
+	}
+	return bar
+}
+
+func (h HeatBuilder) grid() (heatGrid, error) {
+	n := h.data.Len()
+	if h.weights != nil && h.weights.Len() != n {
+		return heatGrid{}, errHeatWeights
+	}
+	if h.x.bins < 1 || h.y.bins < 1 {
+		return heatGrid{}, errHeatBins
+	}
+	var xs, ys, ws []float64
+	for i := 0; i < n; i++ {
+		x, y := h.data.XY(i)
+		if !finite(x) || !finite(y) {
+			continue
+		}
+		w := 1.0
+		if h.weights != nil {
+			w = h.weights.Value(i)
+		}
+		xs, ys, ws = append(xs, x), append(ys, y), append(ws, w)
+	}
+	xedges, err := h.x.edges(xs)
+	if err != nil {
+		return heatGrid{}, err
+	}
+	yedges, err := h.y.edges(ys)
+	if err != nil {
+		return heatGrid{}, err
+	}
+	grid := heatGrid{
+		xedges: xedges,
+		yedges: yedges,
+		cells:  make([][]float64, h.x.bins),
+	}
+	for i := range grid.cells {
+		grid.cells[i] = make([]float64, h.y.bins)
+	}
+	for i, w := range ws {
+		xi, ok := h.x.index(xedges, xs[i])
+		if !ok {
+			continue
+		}
+		yi, ok := h.y.index(yedges, ys[i])
+		if !ok {
+			continue
+		}
+		grid.cells[xi][yi] += w
+	}
+	return grid, nil
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// edges returns the bin edges over the axis range, or the range of
+// the values.
+func (a heatAxis) edges(values []float64) ([]float64, error) {
+	min, max := math.Inf(+1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if a.min != nil {
+		min, max = *a.min, *a.max
+	}
+	if len(values) == 0 && a.min == nil {
+		min, max = 0, 1
+	}
+	if a.log && min <= 0 {
+		return nil, errHeatLog
+	}
+	if min == max {
+		if a.log {
+			min, max = min/2, max*2
+		} else {
+			min, max = min-0.5, max+0.5
+		}
+	}
+	if a.log {
+		min, max = math.Log(min), math.Log(max)
+	}
+	edges := make([]float64, a.bins+1)
+	for i := range edges {
+		edges[i] = min + (max-min)*float64(i)/float64(a.bins)
+		if a.log {
+			edges[i] = math.Exp(edges[i])
+		}
+	}
+	return edges, nil
+}
+
+// index returns the bin of v, if within the edges.
+func (a heatAxis) index(edges []float64, v float64) (int, bool) {
+	min, max := edges[0], edges[len(edges)-1]
+	if v < min || v > max {
+		return 0, false
+	}
+	if a.log {
+		v, min, max = math.Log(v), math.Log(min), math.Log(max)
+	}
+	i := int((v - min) / (max - min) * float64(a.bins))
+	if i == a.bins {
+		// The maximum falls in the last bin.
+		i--
+	}
+	return i, true
+}
+
+func (hm *heatmap) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	for i, col := range hm.cells {
+		for j, w := range col {
+			if w == 0 {
+				continue
+			}
+			x0, x1 := trX(hm.xedges[i]), trX(hm.xedges[i+1])
+			y0, y1 := trY(hm.yedges[j]), trY(hm.yedges[j+1])
+			pts := []vg.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+			c.FillPolygon(hm.bar.color(w), c.ClipPolygonXY(pts))
+		}
+	}
+}
+
+func (hm *heatmap) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return hm.xedges[0], hm.xedges[len(hm.xedges)-1], hm.yedges[0], hm.yedges[len(hm.yedges)-1]
+}
+
+func (b *colorBar) color(w float64) color.Color {
+	i := int((w - b.min) / (b.max - b.min) * float64(len(b.colors)-1))
+	return b.colors[max(0, min(i, len(b.colors)-1))]
+}
+
+// Plot draws the colors in equal steps from min to max.
+func (b *colorBar) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	x0, x1 := trX(0), trX(1)
+	step := (b.max - b.min) / float64(len(b.colors))
+	for i, clr := range b.colors {
+		y0 := trY(b.min + step*float64(i))
+		y1 := trY(b.min + step*float64(i+1))
+		pts := []vg.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+		c.FillPolygon(clr, pts)
+	}
+}
+
+func (b *colorBar) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return 0, 1, b.min, b.max
+}
+
+func (hm *heatmap) colorBar() *colorBar {
+	return hm.bar
+}
+
+// drawColorBar draws the colorbar along the right of the canvas,
+// returning the rest of the canvas for the plot.
+func (builder Builder) drawColorBar(dc draw.Canvas, bar *colorBar) draw.Canvas {
+	p := plot.New()
+	Builder{Plot: p, theme: builder.theme}.applyTheme()
+	p.HideX()
+	p.Y.Label.Text = bar.name
+	p.Add(bar)
+
+	strip := dc
+	strip.Min.X = dc.Max.X - defaultColorBarWidth
+	if title := builder.Plot.Title; title.Text != "" {
+		// Align the colorbar with the plot area, below the title.
+		strip.Max.Y -= title.TextStyle.Height(title.Text) + title.Padding
+	}
+	p.Draw(strip)
+
+	dc.Max.X = strip.Min.X
+	return dc
+}
@@ -15,10 +15,17 @@ type (
 		bins       []loghist.HistogramBin
 		trans      loghist.DataTransformer
 		normalize  bool
+		norm       loghist.Normalization
 		centroids  bool
 		fillColors []color.Color
 		lineColor  color.Color
 		name       string
+		stack      []stackCategory
+	}
+
+	stackCategory struct {
+		name string
+		bins []loghist.HistogramBin
 	}
 
 	nonzeroRange struct {
@@ -42,16 +49,39 @@ func (h HBuilder) EqualBins(xys plotter.XYer, numBins int) HBuilder {
 	return h
 }
 
+// RuleBins bins the points by a binner that chooses its own number
+// of bins, such as loghist.NewFreedmanDiaconisBinner, in the space
+// of the Transformer, which must be set first.
+func (h HBuilder) RuleBins(xys plotter.XYer, binner loghist.Binner) HBuilder {
+	defer recovery.Here()()
+	trans := h.trans
+	if trans == nil {
+		trans = loghist.LinearTransformer{}
+	}
+	h.bins = binner.BinPoints(xys, trans)
+	return h
+}
+
 func (h HBuilder) Bins(bins []loghist.HistogramBin) HBuilder {
 	h.bins = bins
 	return h
 }
 
+// Normalize draws the histogram as a density of unit area, or
+// with Stack, stacks each bin to one.
 func (h HBuilder) Normalize() HBuilder {
 	h.normalize = true
 	return h
 }
 
+// NormalizeAs selects the heights of the bars, as count,
+// probability, density, or density in the space of the Transformer.
+func (h HBuilder) NormalizeAs(norm loghist.Normalization) HBuilder {
+	h.normalize = false
+	h.norm = norm
+	return h
+}
+
 func (h HBuilder) Centroids() HBuilder {
 	h.centroids = true
 	return h
@@ -73,10 +103,15 @@ func (h HBuilder) Name(name string) HBuilder {
 }
 
 func (h HBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	if h.stack != nil {
+		return h.addStacked(builder)
+	}
 	hist := loghist.NewHistogram(h.trans, h.bins)
 
 	if h.normalize {
 		hist.Normalize(1)
+	} else {
+		hist.NormalizeAs(h.norm, 1)
 	}
 	if h.centroids {
 		hist.Centroids()
@@ -84,9 +119,8 @@ func (h HBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plo
 	if h.fillColors != nil {
 		hist.FillColors = h.fillColors
 	}
-	if h.lineColor != nil {
-		hist.LineStyle.Color = h.lineColor
-	}
+	hist.LineStyle.Color = builder.foreground(h.lineColor)
+	hist.LineStyle.Width = builder.lineWidth(0)
 	builder.Plot.Add(hist)
 	return hist, nonzeroRange{hist}, hist, nil
 }
@@ -103,11 +137,12 @@ func (h nonzeroRange) DataRange() (xmin, xmax, ymin, ymax float64) {
 		if bin.Min < xmin {
 			xmin = bin.Min
 		}
-		if bin.Weight > ymax {
-			ymax = bin.Weight
+		height := h.Height(bin)
+		if height > ymax {
+			ymax = height
 		}
-		if bin.Weight > 0 && bin.Weight < ymin {
-			ymin = bin.Weight
+		if height > 0 && height < ymin {
+			ymin = height
 		}
 	}
 	return
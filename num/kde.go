@@ -0,0 +1,310 @@
+package num
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	defaultKDESamples = 200
+
+	// kdeTails extends the estimate this many bandwidths beyond
+	// the data.
+	kdeTails = 3
+)
+
+var errKDESamples = errors.New("num: KDE needs at least two samples")
+
+type (
+	// Kernel is a probability density with mean zero, scaled by
+	// the bandwidth.
+	Kernel func(u float64) float64
+
+	// Bandwidth selects the bandwidth of a kernel density
+	// estimate from the sample.  Weights may be nil, meaning one
+	// each.
+	Bandwidth func(values, weights []float64) float64
+
+	// KDEBuilder draws a kernel density estimate of weighted
+	// data, a plotter.XYer of value and weight.  The default is
+	// a Gaussian kernel of Silverman's bandwidth.
+	KDEBuilder struct {
+		data      plotter.XYer
+		kernel    Kernel
+		bandwidth Bandwidth
+		boundary  *float64
+		samples   int
+		width     vg.Length
+		color     color.Color
+		name      string
+	}
+
+	// density is a kernel density estimate, reflected about the
+	// boundary if there is one.
+	density struct {
+		ws       []weighted
+		total    float64
+		h        float64
+		kernel   Kernel
+		boundary *float64
+	}
+
+	// positiveRange is the range of a line, ignoring Y values of
+	// zero, for log-scale axes.
+	positiveRange struct {
+		plotter.XYs
+	}
+)
+
+func Gaussian(u float64) float64 {
+	return math.Exp(-u*u/2) / math.Sqrt(2*math.Pi)
+}
+
+func Epanechnikov(u float64) float64 {
+	if math.Abs(u) > 1 {
+		return 0
+	}
+	return 0.75 * (1 - u*u)
+}
+
+func Triangular(u float64) float64 {
+	if math.Abs(u) > 1 {
+		return 0
+	}
+	return 1 - math.Abs(u)
+}
+
+func Uniform(u float64) float64 {
+	if math.Abs(u) > 1 {
+		return 0
+	}
+	return 0.5
+}
+
+// Silverman's rule of thumb, 0.9 min(σ, IQR/1.34) n^-1/5, is robust
+// to heavy tails.
+func Silverman(values, weights []float64) float64 {
+	ws := weightedSample(values, weights)
+	sd, n := spread(ws)
+	if iqr := (quantile(ws, 0.75) - quantile(ws, 0.25)) / 1.34; iqr > 0 && iqr < sd {
+		sd = iqr
+	}
+	return positiveBandwidth(0.9 * sd * math.Pow(n, -0.2))
+}
+
+// Scott's rule of thumb, 1.06 σ n^-1/5, is optimal for normal data.
+func Scott(values, weights []float64) float64 {
+	sd, n := spread(weightedSample(values, weights))
+	return positiveBandwidth(1.06 * sd * math.Pow(n, -0.2))
+}
+
+// FixedBandwidth is a constant bandwidth.
+func FixedBandwidth(h float64) Bandwidth {
+	return func([]float64, []float64) float64 {
+		return h
+	}
+}
+
+func KDE(data plotter.XYer) KDEBuilder {
+	return KDEBuilder{
+		data:      data,
+		kernel:    Gaussian,
+		bandwidth: Silverman,
+		samples:   defaultKDESamples,
+	}
+}
+
+func (k KDEBuilder) Kernel(kernel Kernel) KDEBuilder {
+	k.kernel = kernel
+	return k
+}
+
+func (k KDEBuilder) Bandwidth(bandwidth Bandwidth) KDEBuilder {
+	k.bandwidth = bandwidth
+	return k
+}
+
+// Boundary reflects the estimate about a lower bound of the data,
+// so that no density falls below it.
+func (k KDEBuilder) Boundary(lower float64) KDEBuilder {
+	k.boundary = &lower
+	return k
+}
+
+// Positive bounds the estimate at zero, for data such as latency.
+func (k KDEBuilder) Positive() KDEBuilder {
+	return k.Boundary(0)
+}
+
+// Samples sets the number of points at which the estimate is drawn,
+// at least two.
+func (k KDEBuilder) Samples(samples int) KDEBuilder {
+	k.samples = samples
+	return k
+}
+
+func (k KDEBuilder) Width(points float64) KDEBuilder {
+	k.width = vg.Points(points)
+	return k
+}
+
+func (k KDEBuilder) Color(color color.Color) KDEBuilder {
+	k.color = color
+	return k
+}
+
+func (k KDEBuilder) Name(name string) KDEBuilder {
+	k.name = name
+	return k
+}
+
+func (k KDEBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	if k.samples < 2 {
+		return nil, nil, nil, errKDESamples
+	}
+	pts := k.curve(builder.XAxis.logscale, k.samples)
+	if pts == nil {
+		return nil, nil, nil, nil
+	}
+
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	line.LineStyle.Width = builder.lineWidth(k.width)
+	line.LineStyle.Color = builder.foreground(k.color)
+	builder.Plot.Add(line)
+	return line, positiveRange{pts}, nil, nil
+}
+
+// curve evaluates the estimate at n points spanning the data and
+// its tails, on a geometric grid for log-scale X axes.  It is nil
+// without data.
+func (k KDEBuilder) curve(logscale bool, n int) plotter.XYs {
+	scale := valueScale{log: logscale}
+	ws := sortedXYs(k.data)
+	if scale.log {
+		// Values that a log-scale axis cannot show are dropped.
+		for len(ws) != 0 && ws[0].value <= 0 {
+			ws = ws[1:]
+		}
+	}
+	if len(ws) == 0 {
+		return nil
+	}
+	d := newDensity(ws, k.kernel, k.bandwidth(unzip(ws)), k.boundary)
+
+	lo := ws[0].value - kdeTails*d.h
+	hi := ws[len(ws)-1].value + kdeTails*d.h
+	if k.boundary != nil {
+		lo = math.Max(lo, *k.boundary)
+	}
+	if scale.log && lo <= 0 {
+		lo = ws[0].value
+	}
+	var pts plotter.XYs
+	for i := 0; i < n; i++ {
+		x := scale.from(scale.to(lo) + (scale.to(hi)-scale.to(lo))*float64(i)/float64(n-1))
+		pts = append(pts, plotter.XY{X: x, Y: d.at(x)})
+	}
+	return pts
+}
+
+func (k KDEBuilder) namePlot() string {
+	return k.name
+}
+
+func newDensity(ws []weighted, kernel Kernel, h float64, boundary *float64) density {
+	d := density{
+		ws:       ws,
+		h:        h,
+		kernel:   kernel,
+		boundary: boundary,
+	}
+	for _, w := range ws {
+		d.total += w.weight
+	}
+	return d
+}
+
+func (d density) at(x float64) float64 {
+	if d.boundary != nil && x < *d.boundary {
+		return 0
+	}
+	sum := 0.0
+	for _, w := range d.ws {
+		sum += w.weight * d.kernel((x-w.value)/d.h)
+		if d.boundary != nil {
+			// The mass of the kernel below the boundary,
+			// reflected above it.
+			b := *d.boundary
+			sum += w.weight * d.kernel((x+w.value-2*b)/d.h)
+		}
+	}
+	return sum / (d.total * d.h)
+}
+
+func (r positiveRange) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax, _, ymax = plotter.XYRange(r.XYs)
+	ymin = math.Inf(+1)
+	for _, pt := range r.XYs {
+		if pt.Y > 0 {
+			ymin = math.Min(ymin, pt.Y)
+		}
+	}
+	return
+}
+
+// weightedSample pairs values with their weights, sorted by value.
+func weightedSample(values, weights []float64) []weighted {
+	ws := make([]weighted, len(values))
+	for i, v := range values {
+		ws[i] = weighted{value: v, weight: 1}
+		if weights != nil {
+			ws[i].weight = weights[i]
+		}
+	}
+	sort.Slice(ws, func(i, j int) bool {
+		return ws[i].value < ws[j].value
+	})
+	return ws
+}
+
+// unzip separates the values and weights of a sample.
+func unzip(ws []weighted) (values, weights []float64) {
+	values = make([]float64, len(ws))
+	weights = make([]float64, len(ws))
+	for i, w := range ws {
+		values[i], weights[i] = w.value, w.weight
+	}
+	return values, weights
+}
+
+// spread returns the standard deviation and the effective size of a
+// weighted sample.
+func spread(ws []weighted) (sd, n float64) {
+	var total, sum, sumSq, sumWSq float64
+	for _, w := range ws {
+		total += w.weight
+		sum += w.weight * w.value
+		sumSq += w.weight * w.value * w.value
+		sumWSq += w.weight * w.weight
+	}
+	mean := sum / total
+	return math.Sqrt(math.Max(0, sumSq/total-mean*mean)), total * total / sumWSq
+}
+
+// positiveBandwidth substitutes one for the zero bandwidth of a
+// sample with no spread.
+func positiveBandwidth(h float64) float64 {
+	if h > 0 {
+		return h
+	}
+	return 1
+}
@@ -7,34 +7,36 @@ import (
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
 const fsamples = 1000
 
-var (
-	defaultLineWidth = vg.Points(1)
-	defaultLineColor = color.RGBA{R: 0, G: 0, B: 0, A: 255}
-)
-
 type (
 	XYoker interface {
 		XYok(int) (x, y float64, ok bool)
 	}
 
 	LBuilder struct {
-		data   plotter.XYer
-		width  vg.Length
-		color  color.Color
-		name   string
-		smooth bool
+		data         plotter.XYer
+		width        vg.Length
+		color        color.Color
+		name         string
+		smooth       bool
+		lower, upper plotter.XYer
+		bandColor    color.Color
+	}
+
+	// bandThumbnail draws a line over its band.
+	bandThumbnail struct {
+		plot.Thumbnailer
+		fill color.Color
 	}
 )
 
 func Line(data plotter.XYer) LBuilder {
 	return LBuilder{
-		data:  data,
-		width: defaultLineWidth,
-		color: defaultLineColor,
+		data: data,
 	}
 }
 
@@ -58,16 +60,57 @@ func (l LBuilder) Smooth() LBuilder {
 	return l
 }
 
+// Band shades the area between lower and upper series, such as a
+// confidence interval, beneath the line.
+func (l LBuilder) Band(lower, upper plotter.XYer) LBuilder {
+	l.lower = lower
+	l.upper = upper
+	return l
+}
+
+// BandColor fills the band, by default with the line color at a
+// quarter opacity.
+func (l LBuilder) BandColor(color color.Color) LBuilder {
+	l.bandColor = color
+	return l
+}
+
 func (l LBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	if l.lower == nil {
+		thumb, valranger, err = l.addLine(builder)
+		return thumb, valranger, nil, err
+	}
+
+	// The band goes beneath the line.
+	fill := l.bandColor
+	if fill == nil {
+		fill = withAlpha(builder.foreground(l.color), defaultBandAlpha)
+	}
+	band, err := plotter.NewPolygon(bandOutline(l.lower, l.upper))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	band.LineStyle.Width = 0
+	band.Color = fill
+	builder.Plot.Add(band)
+
+	thumb, valranger, err = l.addLine(builder)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return bandThumbnail{thumb, fill}, valranger, nil, nil
+}
+
+func (l LBuilder) addLine(builder Builder) (plot.Thumbnailer, plot.DataRanger, error) {
 	if !l.smooth || l.data.Len() == 0 {
 		line, err := plotter.NewLine(l.data)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
-		line.LineStyle.Width = l.width
-		line.LineStyle.Color = l.color
+		line.LineStyle.Width = builder.lineWidth(l.width)
+		line.LineStyle.Color = builder.foreground(l.color)
 		builder.Plot.Add(line)
-		return line, line, nil, nil
+		return line, line, nil
 	}
 
 	var xs []float64
@@ -85,12 +128,36 @@ func (l LBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plo
 		return sp.At(x)
 	})
 	line.Samples = fsamples
-	line.LineStyle.Width = l.width
-	line.LineStyle.Color = l.color
+	line.LineStyle.Width = builder.lineWidth(l.width)
+	line.LineStyle.Color = builder.foreground(l.color)
 	builder.Plot.Add(line)
-	return line, nil, nil, nil
+	return line, nil, nil
 }
 
 func (l LBuilder) namePlot() string {
 	return l.name
 }
+
+// bandOutline joins the lower series to the upper, reversed.
+func bandOutline(lower, upper plotter.XYer) plotter.XYs {
+	var pts plotter.XYs
+	for i := 0; i < lower.Len(); i++ {
+		x, y := lower.XY(i)
+		pts = append(pts, plotter.XY{X: x, Y: y})
+	}
+	for i := upper.Len() - 1; i >= 0; i-- {
+		x, y := upper.XY(i)
+		pts = append(pts, plotter.XY{X: x, Y: y})
+	}
+	return pts
+}
+
+func (b bandThumbnail) Thumbnail(c *draw.Canvas) {
+	c.FillPolygon(b.fill, []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+	})
+	b.Thumbnailer.Thumbnail(c)
+}
@@ -10,6 +10,7 @@ import (
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
 )
 
 const (
@@ -40,12 +41,30 @@ type (
 		DrawLegend   bool
 		XAxis, YAxis ABuilder
 		Ranger       Ranger
+
+		// AltText replaces the alt text generated from the
+		// title, axis labels and plotter names.
+		AltText         string
+		LongDescription string
+		DataFallback    bool
+
+		theme *essay.Theme
+		ratio float64
 	}
 
 	Ranger interface {
 		DataRange() (xmin, xmax, ymin, ymax float64)
 	}
 
+	legendEntry struct {
+		name  string
+		thumb plot.Thumbnailer
+	}
+
+	// categoryLegend is the thumbnail of a plotter with a legend
+	// entry for each category.
+	categoryLegend []legendEntry
+
 	Plotter interface {
 		namePlot() string
 		addTo(Builder) (p plot.Thumbnailer, v, r plot.DataRanger, e error)
@@ -54,11 +73,27 @@ type (
 
 func (builder Builder) Render(builtin essay.Builtin) (interface{}, error) {
 	defer recovery.Here()()
-	img := builder.Image(essay.PNG)
-	return builtin.RenderImage(img)
+	return builtin.RenderImage(builder.Frame(builtin))
 }
 
-func (builder Builder) setupPlot() {
+// Frame draws the plot in the theme and at the pixel ratio of the
+// essay, as a frame of an essay.Animation.
+func (builder Builder) Frame(builtin essay.Builtin) essay.EncodedImage {
+	if builder.theme == nil {
+		builder = builder.Theme(builtin.Theme())
+	}
+	if builder.ratio == 0 {
+		builder.ratio = builtin.PixelRatio()
+	}
+	return builder.Image(essay.PNG)
+}
+
+// setupPlot adds the plotters to the plot and configures its axes,
+// returning the legend entries of named plotters and the colorbar
+// of the first plotter with one.
+func (builder Builder) setupPlot() (legend []legendEntry, bar *colorBar) {
+	builder.applyTheme()
+
 	minx, miny := math.Inf(+1), math.Inf(+1)
 	maxx, maxy := math.Inf(-1), math.Inf(-1)
 
@@ -68,6 +103,10 @@ func (builder Builder) setupPlot() {
 			panic(err)
 		}
 
+		if cb, ok := valranger.(colorBarer); ok && bar == nil {
+			bar = cb.colorBar()
+		}
+
 		if valranger != nil {
 			xmin, xmax, ymin, ymax := valranger.DataRange()
 
@@ -86,9 +125,19 @@ func (builder Builder) setupPlot() {
 			builder.Plot.Y.Max = math.Min(builder.Plot.Y.Max, ymax)
 		}
 
-		if builder.DrawLegend && thumbnailer != nil {
+		if thumbnailer == nil {
+			continue
+		}
+		entries := []legendEntry{{p.namePlot(), thumbnailer}}
+		if cl, ok := thumbnailer.(categoryLegend); ok {
+			entries = cl
+		}
+		legend = append(legend, entries...)
+		if builder.DrawLegend {
 			// TODO This needs more flexibility
-			builder.Plot.Legend.Add(p.namePlot(), thumbnailer)
+			for _, e := range entries {
+				builder.Plot.Legend.Add(e.name, e.thumb)
+			}
 			builder.Plot.Legend.Padding = defaultLegendPadding
 			builder.Plot.Legend.Top = true
 			builder.Plot.Legend.YOffs = defaultLegendPadding
@@ -150,30 +199,82 @@ func (builder Builder) setupPlot() {
 		builder.Plot.Y.Min = ymin
 		builder.Plot.Y.Max = ymax
 	}
+	return legend, bar
+}
+
+// Thumbnail draws the thumbnail of each category side by side, for
+// legends that show the plotter as one entry.
+func (cl categoryLegend) Thumbnail(c *draw.Canvas) {
+	width := (c.Max.X - c.Min.X) / vg.Length(len(cl))
+	for i, e := range cl {
+		part := *c
+		part.Min.X = c.Min.X + vg.Length(i)*width
+		part.Max.X = part.Min.X + width
+		e.thumb.Thumbnail(&part)
+	}
 }
 
 func (builder Builder) Image(kind essay.ImageKind) essay.EncodedImage {
 	defer recovery.Here()()
-	builder.setupPlot()
+	alt := builder.AltText
+	if alt == "" {
+		alt = builder.defaultAlt()
+	}
+	_, bar := builder.setupPlot()
 
 	w := vg.Length(builder.Width)
 	h := vg.Length(builder.Height)
 
+	c := newCanvas(kind, w, h, builder.ratio)
+	dc := draw.New(c)
+	if bar != nil {
+		dc = builder.drawColorBar(dc, bar)
+	}
+	builder.Plot.Draw(dc)
+
 	var buf bytes.Buffer
-	if writer, err := builder.Plot.WriterTo(w, h, string(kind)); err != nil {
-		panic(err)
-	} else if _, err := writer.WriteTo(&buf); err != nil {
+	if _, err := c.WriteTo(&buf); err != nil {
 		panic(err)
 	}
 
-	return essay.EncodedImage{
+	img := essay.EncodedImage{
 		Kind: kind,
 		Bounds: image.Rectangle{
 			Min: image.Point{},
 			Max: image.Pt(builder.Width, builder.Height),
 		},
-		Data: buf.Bytes(),
+		Data:        buf.Bytes(),
+		Alt:         alt,
+		Description: builder.LongDescription,
 	}
+	if builder.DataFallback {
+		img.Fallback = builder.fallback()
+		if omitted := builder.untabled(); len(omitted) != 0 {
+			img.Alt += "; the data table omits " + joinNames(omitted)
+		}
+	}
+	return img
+}
+
+// newCanvas returns a canvas for an image of the given kind.
+// PNG images are drawn at ratio times the default resolution, where
+// zero means one.
+func newCanvas(kind essay.ImageKind, w, h vg.Length, ratio float64) vg.CanvasWriterTo {
+	defer recovery.Here()()
+	if kind == essay.PNG {
+		// The image is w x h points, displayed as as many
+		// CSS pixels.
+		if ratio == 0 {
+			ratio = 1
+		}
+		c := vgimg.NewWith(vgimg.UseWH(w, h), vgimg.UseDPI(int(vgimg.DefaultDPI*ratio+0.5)))
+		return vgimg.PngCanvas{Canvas: c}
+	}
+	c, err := draw.NewFormattedCanvas(w, h, string(kind))
+	if err != nil {
+		panic(err)
+	}
+	return c
 }
 
 func Plot(p *plot.Plot, w, h int) Builder {
@@ -240,3 +341,31 @@ func (b Builder) Legend() Builder {
 	b.DrawLegend = true
 	return b
 }
+
+func (b Builder) Alt(text string) Builder {
+	b.AltText = text
+	return b
+}
+
+func (b Builder) Description(text string) Builder {
+	b.LongDescription = text
+	return b
+}
+
+// PixelRatio renders PNG images at ratio times the gonum default
+// of 96 pixels per inch, for high-density displays.  The displayed
+// size is unchanged.  Zero means the ratio of the essay, when
+// rendered in one, otherwise one.
+func (b Builder) PixelRatio(ratio float64) Builder {
+	b.ratio = ratio
+	return b
+}
+
+// DataTable adds a hidden table of the plotted data, for screen
+// readers.  Lines, scatters, functions, histograms, nominal bars,
+// ECDFs, QQ plots and KDEs are listed; the alt text names the
+// other plotters, which are omitted.
+func (b Builder) DataTable() Builder {
+	b.DataFallback = true
+	return b
+}
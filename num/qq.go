@@ -0,0 +1,111 @@
+package num
+
+import (
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type (
+	// QQBuilder draws the quantiles of weighted data, a
+	// plotter.XYer of value and weight, on the Y axis, against
+	// the same quantiles of a reference on the X axis, with the
+	// line Y = X for comparison.
+	QQBuilder struct {
+		data     plotter.XYer
+		quantile func(p float64) float64
+		other    plotter.XYer
+		radius   vg.Length
+		color    color.Color
+		name     string
+	}
+)
+
+// QQ compares data with a theoretical distribution, given by its
+// quantile function, such as distuv.Normal.Quantile.
+func QQ(data plotter.XYer, quantile func(p float64) float64) QQBuilder {
+	return QQBuilder{
+		data:     data,
+		quantile: quantile,
+		radius:   defaultScatterRadius,
+	}
+}
+
+// QQSamples compares data with a second sample of value and weight.
+func QQSamples(data, other plotter.XYer) QQBuilder {
+	return QQBuilder{
+		data:   data,
+		other:  other,
+		radius: defaultScatterRadius,
+	}
+}
+
+func (q QQBuilder) Radius(points float64) QQBuilder {
+	q.radius = vg.Points(points)
+	return q
+}
+
+func (q QQBuilder) Color(color color.Color) QQBuilder {
+	q.color = color
+	return q
+}
+
+func (q QQBuilder) Name(name string) QQBuilder {
+	q.name = name
+	return q
+}
+
+func (q QQBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	pts := q.points()
+
+	diagonal := plotter.NewFunction(func(x float64) float64 { return x })
+	diagonal.LineStyle.Color = builder.currentTheme().Muted
+	diagonal.LineStyle.Width = builder.lineWidth(0)
+	diagonal.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	builder.Plot.Add(diagonal)
+
+	scatter, err := plotter.NewScatter(pts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	scatter.GlyphStyle = draw.GlyphStyle{
+		Color:  builder.foreground(q.color),
+		Radius: q.radius,
+		Shape:  defaultScatterShape,
+	}
+	builder.Plot.Add(scatter)
+	return scatter, scatter, nil, nil
+}
+
+func (q QQBuilder) namePlot() string {
+	return q.name
+}
+
+// points pairs the reference quantile of each value with the value.
+// Each value is plotted at the middle of its share of the weight.
+func (q QQBuilder) points() plotter.XYs {
+	ref := q.quantile
+	if q.other != nil {
+		other := sortedXYs(q.other)
+		ref = func(p float64) float64 {
+			return quantile(other, p)
+		}
+	}
+
+	ws := sortedXYs(q.data)
+	total := 0.0
+	for _, w := range ws {
+		total += w.weight
+	}
+	var pts plotter.XYs
+	cum := 0.0
+	for _, w := range ws {
+		p := (cum + w.weight/2) / total
+		cum += w.weight
+		pts = append(pts, plotter.XY{X: ref(p), Y: w.value})
+	}
+	return pts
+}
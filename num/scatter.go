@@ -11,7 +11,6 @@ import (
 
 var (
 	defaultScatterRadius = vg.Points(1)
-	defaultScatterColor  = color.RGBA{R: 0, G: 0, B: 0, A: 255}
 	defaultScatterShape  = draw.CircleGlyph{}
 )
 
@@ -23,6 +22,21 @@ type (
 		shape  draw.GlyphDrawer
 		style  func(int) draw.GlyphStyle
 		name   string
+
+		xerrors, yerrors *errorValues
+	}
+
+	// errorValues are the distances of error bars below and
+	// above each point.
+	errorValues struct {
+		low, high plotter.Valuer
+	}
+
+	// errorThumbnail draws the error bars through the glyph.
+	errorThumbnail struct {
+		plot.Thumbnailer
+		line         draw.LineStyle
+		xbars, ybars bool
 	}
 )
 
@@ -30,7 +44,6 @@ func Scatter(data plotter.XYer) SBuilder {
 	return SBuilder{
 		data:   data,
 		radius: defaultScatterRadius,
-		color:  defaultScatterColor,
 		shape:  defaultScatterShape,
 	}
 }
@@ -64,6 +77,31 @@ func (s SBuilder) Name(name string) SBuilder {
 	return s
 }
 
+// YError draws symmetric error bars of the given distance above
+// and below each point.
+func (s SBuilder) YError(err plotter.Valuer) SBuilder {
+	return s.YErrors(err, err)
+}
+
+// YErrors draws error bars from low below to high above each point.
+func (s SBuilder) YErrors(low, high plotter.Valuer) SBuilder {
+	s.yerrors = &errorValues{low: low, high: high}
+	return s
+}
+
+// XError draws symmetric error bars of the given distance left and
+// right of each point.
+func (s SBuilder) XError(err plotter.Valuer) SBuilder {
+	return s.XErrors(err, err)
+}
+
+// XErrors draws error bars from low left to high right of each
+// point.
+func (s SBuilder) XErrors(low, high plotter.Valuer) SBuilder {
+	s.xerrors = &errorValues{low: low, high: high}
+	return s
+}
+
 func (s SBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
 	scatter, err := plotter.NewScatter(s.data)
 	if err != nil {
@@ -74,7 +112,7 @@ func (s SBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plo
 		scatter.GlyphStyleFunc = func(i int) draw.GlyphStyle {
 			gs := s.style(i)
 			if gs.Color == nil {
-				gs.Color = s.color
+				gs.Color = builder.foreground(s.color)
 			}
 			if gs.Shape == nil {
 				gs.Shape = s.shape
@@ -87,12 +125,67 @@ func (s SBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plo
 	} else {
 		scatter.GlyphStyle.Shape = s.shape
 		scatter.GlyphStyle.Radius = s.radius
-		scatter.GlyphStyle.Color = s.color
+		scatter.GlyphStyle.Color = builder.foreground(s.color)
+	}
+	if s.xerrors == nil && s.yerrors == nil {
+		builder.Plot.Add(scatter)
+		return scatter, scatter, nil, nil
+	}
+
+	et := errorThumbnail{
+		Thumbnailer: scatter,
+		line: draw.LineStyle{
+			Color: builder.foreground(s.color),
+			Width: builder.lineWidth(0),
+		},
+	}
+	if s.yerrors != nil {
+		bars, err := plotter.NewYErrorBars(struct {
+			plotter.XYer
+			plotter.YErrorer
+		}{s.data, s.yerrors})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bars.LineStyle = et.line
+		builder.Plot.Add(bars)
+		et.ybars = true
+	}
+	if s.xerrors != nil {
+		bars, err := plotter.NewXErrorBars(struct {
+			plotter.XYer
+			plotter.XErrorer
+		}{s.data, s.xerrors})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		bars.LineStyle = et.line
+		builder.Plot.Add(bars)
+		et.xbars = true
 	}
 	builder.Plot.Add(scatter)
-	return scatter, scatter, nil, nil
+	return et, scatter, nil, nil
 }
 
 func (s SBuilder) namePlot() string {
 	return s.name
 }
+
+func (e *errorValues) XError(i int) (float64, float64) {
+	return e.low.Value(i), e.high.Value(i)
+}
+
+func (e *errorValues) YError(i int) (float64, float64) {
+	return e.low.Value(i), e.high.Value(i)
+}
+
+func (t errorThumbnail) Thumbnail(c *draw.Canvas) {
+	center := c.Center()
+	if t.ybars {
+		c.StrokeLine2(t.line, center.X, c.Min.Y, center.X, c.Max.Y)
+	}
+	if t.xbars {
+		c.StrokeLine2(t.line, c.Min.X, center.Y, c.Max.X, center.Y)
+	}
+	t.Thumbnailer.Thumbnail(c)
+}
@@ -0,0 +1,170 @@
+package num
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/jmacd/essay/lib/gonum/loghist"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+type (
+	// Layer is one category of a stacked area chart, with a Y
+	// value at each X of the chart.
+	Layer struct {
+		Name  string
+		Y     []float64
+		Color color.Color
+	}
+
+	// AreaBuilder stacks layers over a shared X grid, each
+	// filled from the top of the layer below, with a legend entry
+	// for each layer.
+	AreaBuilder struct {
+		x         []float64
+		layers    []Layer
+		normalize bool
+	}
+
+	areaRange struct {
+		x, totals []float64
+	}
+)
+
+func StackedArea(x []float64, layers ...Layer) AreaBuilder {
+	return AreaBuilder{
+		x:      x,
+		layers: layers,
+	}
+}
+
+func (a AreaBuilder) Add(layers ...Layer) AreaBuilder {
+	a.layers = append(a.layers, layers...)
+	return a
+}
+
+// Normalize stacks each X to one, showing the fraction of the
+// total in each layer.
+func (a AreaBuilder) Normalize() AreaBuilder {
+	a.normalize = true
+	return a
+}
+
+func (a AreaBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	for _, layer := range a.layers {
+		if len(layer.Y) != len(a.x) {
+			return nil, nil, nil, fmt.Errorf("num: layer %q values differ in length from the x values", layer.Name)
+		}
+	}
+	totals := make([]float64, len(a.x))
+	for _, layer := range a.layers {
+		for i := range a.x {
+			totals[i] += layer.Y[i]
+		}
+	}
+	scale := func(i int) float64 {
+		if a.normalize && totals[i] != 0 {
+			return 1 / totals[i]
+		}
+		return 1
+	}
+
+	line := draw.LineStyle{
+		Color: builder.foreground(nil),
+		Width: builder.lineWidth(0),
+	}
+	var legend categoryLegend
+	base := make([]float64, len(a.x))
+	for l, layer := range a.layers {
+		var lower, upper plotter.XYs
+		for i, x := range a.x {
+			lower = append(lower, plotter.XY{X: x, Y: base[i]})
+			base[i] += layer.Y[i] * scale(i)
+			upper = append(upper, plotter.XY{X: x, Y: base[i]})
+		}
+		area, err := plotter.NewPolygon(bandOutline(lower, upper))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fill := categoryColor(layer.Color, l)
+		area.Color = fill
+		area.LineStyle = line
+		builder.Plot.Add(area)
+		legend = append(legend, legendEntry{layer.Name, fillThumbnail{fill, line}})
+	}
+	if a.normalize {
+		for i := range totals {
+			totals[i] = 1
+		}
+	}
+	return legend, areaRange{a.x, totals}, nil, nil
+}
+
+func (a AreaBuilder) namePlot() string {
+	return ""
+}
+
+// Stack adds a category to a stacked histogram, in which each bin
+// is split by category and colored by FillColors, one per category.
+// The bins of every category must have the same bounds, as from a
+// loghist.FixedBinner.  With Normalize, each bin stacks to one, and
+// with NormalizeAs the bars are normalized as one histogram.
+func (h HBuilder) Stack(name string, bins []loghist.HistogramBin) HBuilder {
+	h.stack = append(h.stack, stackCategory{name, bins})
+	return h
+}
+
+func (h HBuilder) addStacked(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	var categories [][]loghist.HistogramBin
+	for _, c := range h.stack {
+		categories = append(categories, c.bins)
+	}
+	sh, err := loghist.NewStackedHistogram(categories)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sh.Transform = h.trans
+	sh.NormalizeAs(h.norm, 1)
+	if h.normalize {
+		sh.Normalize()
+	}
+	sh.LineStyle.Color = builder.foreground(h.lineColor)
+	sh.LineStyle.Width = builder.lineWidth(0)
+
+	var legend categoryLegend
+	for i, c := range h.stack {
+		var fill color.Color
+		if i < len(h.fillColors) {
+			fill = h.fillColors[i]
+		}
+		sh.FillColors = append(sh.FillColors, categoryColor(fill, i))
+		legend = append(legend, legendEntry{c.name, sh.CategoryThumbnail(i)})
+	}
+	builder.Plot.Add(sh)
+	return legend, sh, sh, nil
+}
+
+// categoryColor is c, or the default color of category i.
+func categoryColor(c color.Color, i int) color.Color {
+	if c != nil {
+		return c
+	}
+	return plotutil.Color(i)
+}
+
+func (r areaRange) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax = math.Inf(+1), math.Inf(-1)
+	for _, x := range r.x {
+		xmin = math.Min(xmin, x)
+		xmax = math.Max(xmax, x)
+	}
+	ymax = math.Inf(-1)
+	for _, t := range r.totals {
+		ymax = math.Max(ymax, t)
+	}
+	return xmin, xmax, 0, ymax
+}
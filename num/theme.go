@@ -0,0 +1,77 @@
+package num
+
+import (
+	"image/color"
+
+	"github.com/jmacd/essay"
+	"github.com/jmacd/essay/internal/recovery"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+)
+
+// defaultFontSize is the size of gonum plot titles and axis labels,
+// relative to which the theme scales plot text.
+const defaultFontSize = 12
+
+// Theme styles the plot, which otherwise takes the theme of the
+// essay it renders in, or the light theme when drawn by Image.
+func (b Builder) Theme(t essay.Theme) Builder {
+	b.theme = &t
+	return b
+}
+
+func (b Builder) currentTheme() essay.Theme {
+	defer recovery.Here()()
+	if b.theme != nil {
+		return *b.theme
+	}
+	t, err := essay.LookupTheme(essay.LightTheme)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// lineWidth is the width of lines whose plotter did not set one.
+func (b Builder) lineWidth(width vg.Length) vg.Length {
+	if width != 0 {
+		return width
+	}
+	return vg.Points(b.currentTheme().LineWidth)
+}
+
+// foreground is the color of plotters that did not set one.
+func (b Builder) foreground(c color.Color) color.Color {
+	if c != nil {
+		return c
+	}
+	return b.currentTheme().Foreground
+}
+
+// applyTheme colors the plot and sets its fonts.
+func (b Builder) applyTheme() {
+	t := b.currentTheme()
+	p := b.Plot
+	scale := t.FontSize / defaultFontSize
+
+	style := func(s *text.Style) {
+		s.Color = t.Foreground
+		s.Font = font.From(font.Font{
+			Typeface: plot.DefaultFont.Typeface,
+			Variant:  font.Variant(t.PlotFont),
+		}, s.Font.Size*vg.Length(scale))
+	}
+
+	p.BackgroundColor = t.Background
+	style(&p.Title.TextStyle)
+	style(&p.Legend.TextStyle)
+	for _, axis := range []*plot.Axis{&p.X, &p.Y} {
+		axis.Color = t.Foreground
+		axis.LineStyle.Color = t.Foreground
+		axis.Tick.LineStyle.Color = t.Foreground
+		style(&axis.Label.TextStyle)
+		style(&axis.Tick.Label)
+	}
+}
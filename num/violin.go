@@ -0,0 +1,164 @@
+package num
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// violinSamples is the number of points on each side of a violin.
+const violinSamples = 100
+
+type (
+	// ViolinBuilder draws the density of each sample, mirrored
+	// about its nominal X value, with a line at the median.  The
+	// density is a Gaussian kernel density estimate unless the
+	// sample has a Density.  On a log-scale Y axis the density
+	// is of the logarithm, so that violins keep their shape.
+	ViolinBuilder struct {
+		samples []Sample
+		width   float64
+		color   color.Color
+		name    string
+	}
+
+	violin struct {
+		boxStats
+
+		// values and density outline the violin, in data
+		// coordinates, scaled to a maximum density of one.
+		values, density []float64
+	}
+
+	violins struct {
+		violins []violin
+		width   float64
+		fill    color.Color
+		line    draw.LineStyle
+	}
+)
+
+func Violin(samples ...Sample) ViolinBuilder {
+	return ViolinBuilder{
+		samples: samples,
+		width:   defaultBoxWidth,
+	}
+}
+
+// Width sets the widest point of each violin, as a fraction of the
+// distance between nominal values.
+func (v ViolinBuilder) Width(fraction float64) ViolinBuilder {
+	v.width = fraction
+	return v
+}
+
+// Color fills the violins.
+func (v ViolinBuilder) Color(color color.Color) ViolinBuilder {
+	v.color = color
+	return v
+}
+
+func (v ViolinBuilder) Name(name string) ViolinBuilder {
+	v.name = name
+	return v
+}
+
+func (v ViolinBuilder) addTo(builder Builder) (thumb plot.Thumbnailer, valranger, plotranger plot.DataRanger, err error) {
+	pos, err := samplePositions(builder, v.samples)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	scale := valueScale{log: builder.YAxis.logscale}
+	vs := &violins{
+		width: v.width,
+		fill:  v.color,
+		line: draw.LineStyle{
+			Color: builder.foreground(nil),
+			Width: builder.lineWidth(0),
+		},
+	}
+	for i, s := range v.samples {
+		ws, err := scale.sorted(s)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		vs.violins = append(vs.violins, newViolin(pos[i], s, ws, scale))
+	}
+	builder.Plot.Add(vs)
+	return vs, vs, nil, nil
+}
+
+func (v ViolinBuilder) namePlot() string {
+	return v.name
+}
+
+// newViolin samples the density over the range of the sample.
+func newViolin(pos float64, s Sample, ws []weighted, scale valueScale) violin {
+	vl := violin{boxStats: boxSummary(pos, ws, scale)}
+
+	density := newDensity(ws, Gaussian, Silverman(unzip(ws)), nil).at
+	if s.Density != nil {
+		density = func(x float64) float64 {
+			v := scale.from(x)
+			if scale.log {
+				// The density of the logarithm.
+				return s.Density.ProbDensity(v) * v
+			}
+			return s.Density.ProbDensity(v)
+		}
+	}
+
+	lo, hi := ws[0].value, ws[len(ws)-1].value
+	peak := 0.0
+	for i := 0; i < violinSamples; i++ {
+		x := lo + (hi-lo)*float64(i)/(violinSamples-1)
+		d := density(x)
+		peak = math.Max(peak, d)
+		vl.values = append(vl.values, scale.from(x))
+		vl.density = append(vl.density, d)
+	}
+	if peak > 0 {
+		for i := range vl.density {
+			vl.density[i] /= peak
+		}
+	}
+	return vl
+}
+
+func (vs *violins) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	for _, vl := range vs.violins {
+		half := vs.width / 2
+		var outline []vg.Point
+		for i, v := range vl.values {
+			outline = append(outline, vg.Point{X: trX(vl.pos - half*vl.density[i]), Y: trY(v)})
+		}
+		for i := len(vl.values) - 1; i >= 0; i-- {
+			outline = append(outline, vg.Point{X: trX(vl.pos + half*vl.density[i]), Y: trY(vl.values[i])})
+		}
+		if vs.fill != nil {
+			c.FillPolygon(vs.fill, c.ClipPolygonXY(outline))
+		}
+		c.StrokeLines(vs.line, c.ClipLinesXY(append(outline, outline[0]))...)
+
+		median := trY(vl.median)
+		c.StrokeLines(vs.line, c.ClipLinesXY(
+			[]vg.Point{{X: trX(vl.pos - half/2), Y: median}, {X: trX(vl.pos + half/2), Y: median}},
+		)...)
+	}
+}
+
+func (vs *violins) DataRange() (xmin, xmax, ymin, ymax float64) {
+	var stats []boxStats
+	for _, vl := range vs.violins {
+		stats = append(stats, vl.boxStats)
+	}
+	return statsRange(stats)
+}
+
+func (vs *violins) Thumbnail(c *draw.Canvas) {
+	fillThumbnail{vs.fill, vs.line}.Thumbnail(c)
+}
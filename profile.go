@@ -0,0 +1,129 @@
+package essay
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const (
+	essayEntry     = "essay"
+	sectionEntry   = "section"
+	rendererEntry  = "renderer"
+	displayerEntry = "displayer"
+
+	heapAllocsMetric = "/gc/heap/allocs:bytes"
+)
+
+type (
+	// ProfileEntry is the cost of rendering one section or
+	// Renderer, including everything nested inside it.
+	ProfileEntry struct {
+		Kind   string        `json:"kind"`
+		Path   string        `json:"path"`
+		Name   string        `json:"name"`
+		Depth  int           `json:"depth"`
+		Wall   time.Duration `json:"wall_ns"`
+		Alloc  uint64        `json:"alloc_bytes"`
+		Output uint64        `json:"output_bytes"`
+	}
+)
+
+// profile starts an entry, returning the function that completes
+// it with the size of the output written, plus any output
+// returned for the caller to write.
+func (e *Essay) profile(kind, name string) func(*interface{}) {
+	idx := len(e.profiles)
+	e.profiles = append(e.profiles, ProfileEntry{
+		Kind:  kind,
+		Path:  e.sectionPath(),
+		Name:  name,
+		Depth: e.profileDepth,
+	})
+	e.profileDepth++
+	start := time.Now()
+	alloc := heapAllocs()
+	written := e.out.n
+
+	return func(out *interface{}) {
+		e.profileDepth--
+		entry := &e.profiles[idx]
+		entry.Wall = time.Since(start)
+		entry.Alloc = heapAllocs() - alloc
+		entry.Output = uint64(e.out.n - written)
+		switch t := (*out).(type) {
+		case template.HTML:
+			entry.Output += uint64(len(t))
+		case string:
+			entry.Output += uint64(len(t))
+		}
+	}
+}
+
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// Profile returns the entries recorded while rendering, in
+// document order.
+func (e *Essay) Profile() []ProfileEntry {
+	return e.profiles
+}
+
+func (e *Essay) appendix() (interface{}, error) {
+	defer recovery.Here()()
+	if !e.config.ProfileAppendix {
+		return "", nil
+	}
+	return e.execute("profile.html", e.profiles)
+}
+
+func (e *Essay) writeProfile() error {
+	data, err := json.MarshalIndent(e.profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(e.config.Dir, "profile.json", data); err != nil {
+		return err
+	}
+	return writeFile(e.config.Dir, "profile.txt", []byte(profileSummary(e.profiles)))
+}
+
+func profileSummary(entries []ProfileEntry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%12s %12s %12s  %s\n", "WALL", "ALLOC", "OUTPUT", "ITEM")
+	for _, p := range entries {
+		fmt.Fprintf(&sb, "%12s %12s %12s  %s%s %s\n",
+			p.Wall.Round(time.Millisecond),
+			byteSize(p.Alloc),
+			byteSize(p.Output),
+			strings.Repeat("  ", p.Depth),
+			p.Kind,
+			p.Name,
+		)
+	}
+	return sb.String()
+}
+
+func byteSize(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,179 @@
+package essay
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+type (
+	// Provenance records how an essay was built: the program,
+	// its dependencies, when it ran, and the seeds and
+	// parameters its writer registered with Param.
+	Provenance struct {
+		Title        string    `json:"title"`
+		Timestamp    time.Time `json:"timestamp"`
+		GoVersion    string    `json:"go_version"`
+		Program      string    `json:"program,omitempty"`
+		Revision     string    `json:"revision,omitempty"`
+		RevisionTime string    `json:"revision_time,omitempty"`
+		Modified     bool      `json:"modified,omitempty"`
+		Main         *Module   `json:"main,omitempty"`
+		Modules      []Module  `json:"modules,omitempty"`
+		Params       []Param   `json:"params,omitempty"`
+	}
+
+	Module struct {
+		Path    string `json:"path"`
+		Version string `json:"version"`
+		Sum     string `json:"sum,omitempty"`
+	}
+
+	Param struct {
+		Section string      `json:"section,omitempty"`
+		Name    string      `json:"name"`
+		Value   interface{} `json:"value"`
+	}
+
+	// paramRenderer registers its Param when rendered, so that
+	// it is recorded with the path where it is placed in the
+	// document, not the path being rendered when it was added.
+	paramRenderer struct {
+		*Essay
+		Param
+	}
+)
+
+func newProvenance(title string) *Provenance {
+	p := &Provenance{
+		Title:     title,
+		Timestamp: time.Now().UTC(),
+		GoVersion: runtime.Version(),
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return p
+	}
+	p.Program = info.Path
+	p.Main = &Module{
+		Path:    info.Main.Path,
+		Version: info.Main.Version,
+		Sum:     info.Main.Sum,
+	}
+	for _, dep := range info.Deps {
+		mod := Module{
+			Path:    dep.Path,
+			Version: dep.Version,
+			Sum:     dep.Sum,
+		}
+		if dep.Replace != nil {
+			mod.Version = dep.Replace.Version
+			mod.Sum = dep.Replace.Sum
+		}
+		p.Modules = append(p.Modules, mod)
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			p.Revision = setting.Value
+		case "vcs.time":
+			p.RevisionTime = setting.Value
+		case "vcs.modified":
+			p.Modified = setting.Value == "true"
+		}
+	}
+	return p
+}
+
+// Param registers a seed or parameter used to produce the
+// document, recorded with the section path where it appears.
+// Registering the same name in the same section again replaces
+// its value.
+func (doc *structuredDoc) Param(name string, value interface{}) {
+	doc.add(paramRenderer{
+		Essay: doc.Essay,
+		Param: Param{
+			Name:  name,
+			Value: value,
+		},
+	})
+}
+
+// Seed returns the named random seed, which is the given default
+// unless Config.Seed overrides it, and registers it as a Param.
+// An override is hashed with the name, so that differently named
+// streams stay independent.
+func (doc *structuredDoc) Seed(name string, seed uint64) uint64 {
+	if doc.config.Seed != 0 {
+		seed = deriveSeed(doc.config.Seed, name)
+	}
+	doc.Param(name+" seed", seed)
+	return seed
+}
+
+// deriveSeed returns the seed of the named stream for an override.
+func deriveSeed(override uint64, name string) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], override)
+	h.Write(buf[:])
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+func (p paramRenderer) Render(Builtin) (interface{}, error) {
+	param := p.Param
+	param.Section = p.sectionPath()
+	for i, q := range p.provenance.Params {
+		if q.Section == param.Section && q.Name == param.Name {
+			p.provenance.Params[i] = param
+			return "", nil
+		}
+	}
+	p.provenance.Params = append(p.provenance.Params, param)
+	return "", nil
+}
+
+// Provenance returns the build provenance recorded so far.
+func (e *Essay) Provenance() Provenance {
+	return *e.provenance
+}
+
+func (e *Essay) footer() (interface{}, error) {
+	defer recovery.Here()()
+	return e.execute("footer.html", e.provenance)
+}
+
+// markdown returns the footer as Markdown paragraphs.
+func (p *Provenance) markdown() []string {
+	line := fmt.Sprintf("Built %s with %s", p.Timestamp.Format("2006-01-02 15:04:05 MST"), p.GoVersion)
+	if p.Program != "" {
+		line += " from " + p.Program
+	}
+	if p.Revision != "" {
+		line += " at revision `" + p.Revision + "`"
+	}
+	paras := []string{"---", line}
+	for _, param := range p.Params {
+		name := param.Name
+		if param.Section != "" {
+			name = param.Section + ": " + name
+		}
+		paras = append(paras, fmt.Sprintf("- %s: `%v`", name, param.Value))
+	}
+	return paras
+}
+
+func (e *Essay) writeProvenance() error {
+	data, err := json.MarshalIndent(e.provenance, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(e.config.Dir, "provenance.json", data)
+}
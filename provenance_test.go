@@ -0,0 +1,26 @@
+package essay
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedOverride(t *testing.T) {
+	e, err := New(Config{Title: "Seeds", Backend: MarkdownBackend, Seed: 7})
+	require.NoError(t, err)
+
+	a := e.Seed("a", 1)
+	b := e.Seed("b", 1)
+	require.NotEqual(t, a, b)
+	require.NotEqual(t, uint64(7), a)
+	require.Equal(t, a, e.Seed("a", 2))
+
+	_, err = e.WriteTo(io.Discard)
+	require.NoError(t, err)
+	params := e.Provenance().Params
+	require.Len(t, params, 2)
+	require.Equal(t, Param{Name: "a seed", Value: a}, params[0])
+	require.Equal(t, Param{Name: "b seed", Value: b}, params[1])
+}
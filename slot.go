@@ -0,0 +1,57 @@
+package essay
+
+import (
+	"sync"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+type (
+	// Slot is a Document reserved for content produced later,
+	// typically by another goroutine.  A Slot is placed like
+	// any other body, e.g., doc.Section(name, slot) for a
+	// placeholder section, and renders in that position
+	// regardless of when it is filled.  Rendering waits for
+	// Done, which must be called exactly once the slot is full.
+	Slot interface {
+		Document
+		Done()
+	}
+
+	slotRenderer struct {
+		once sync.Once
+		done chan struct{}
+		structuredDoc
+	}
+)
+
+// Slot returns a new Slot.  Slots, like every Document, are safe
+// for concurrent use, but content added concurrently to one
+// Document appears in nondeterministic order; give each goroutine
+// its own Slot to keep the output deterministic.  Place the Slot
+// before filling it, so that its Depth is known.
+func (doc *structuredDoc) Slot() Slot {
+	slot := &slotRenderer{
+		done: make(chan struct{}),
+	}
+	slot.Essay = doc.Essay
+	return slot
+}
+
+// place records the depth of the slot's contents, those of the
+// document it is added to.
+func (s *slotRenderer) place(doc *structuredDoc) {
+	s.level = doc.Depth()
+}
+
+func (s *slotRenderer) Done() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *slotRenderer) Render(Builtin) (interface{}, error) {
+	defer recovery.Here()()
+	<-s.done
+	return s.body(s.contents())
+}
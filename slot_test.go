@@ -0,0 +1,41 @@
+package essay
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSlotsFilledConcurrently fills Slots from goroutines while the
+// essay renders; run it with -race.
+func TestSlotsFilledConcurrently(t *testing.T) {
+	e, err := New(Config{Title: "Slots", Backend: MarkdownBackend})
+	require.NoError(t, err)
+
+	const count = 8
+	depths := make([]int, count)
+	for i := 0; i < count; i++ {
+		slot := e.Slot()
+		e.Section(fmt.Sprint("Section ", i), slot)
+		go func(i int) {
+			defer slot.Done()
+			depths[i] = slot.Depth()
+			slot.Section("Inner", fmt.Sprint("inner ", i))
+			slot.Note(fmt.Sprint("note ", i))
+		}(i)
+	}
+
+	var buf strings.Builder
+	_, err = e.WriteTo(&buf)
+	require.NoError(t, err)
+
+	out := buf.String()
+	for i := 0; i < count; i++ {
+		require.Equal(t, 2, depths[i])
+		require.Contains(t, out, fmt.Sprintf("## Section %d\n", i))
+		require.Contains(t, out, "### Inner\n")
+		require.Less(t, strings.Index(out, fmt.Sprint("## Section ", i)), strings.Index(out, fmt.Sprint("note ", i)))
+	}
+}
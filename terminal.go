@@ -0,0 +1,394 @@
+package essay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jmacd/essay/internal/recovery"
+
+	_ "image/gif"
+)
+
+const (
+	// AutoGraphics chooses terminal graphics from the
+	// environment.
+	AutoGraphics  Graphics = ""
+	NoGraphics    Graphics = "none"
+	KittyGraphics Graphics = "kitty"
+	SixelGraphics Graphics = "sixel"
+
+	defaultTerminalWidth = 80
+	tableCellWidth       = 32
+	kittyChunkSize       = 4096
+)
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+type (
+	// Graphics is a terminal image protocol.
+	Graphics string
+
+	// terminal writes the essay as plain text, for reading in a
+	// terminal.
+	terminal struct {
+		essay    *Essay
+		width    int
+		graphics Graphics
+		prefix   []string
+	}
+)
+
+func writeTerminal(e *Essay) error {
+	var out interface{}
+	defer e.descend().ascend()
+	defer e.profile(essayEntry, e.config.Title)(&out)
+
+	t := &terminal{
+		essay:    e,
+		width:    terminalWidth(),
+		graphics: terminalGraphics(e.config.Graphics),
+	}
+	return e.walkNode(t, sectionNode, e.config.Title, e.contents())
+}
+
+func terminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	return defaultTerminalWidth
+}
+
+func terminalGraphics(g Graphics) Graphics {
+	if g != AutoGraphics {
+		return g
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty":
+		return KittyGraphics
+	case strings.Contains(term, "sixel") || term == "mlterm" || os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return SixelGraphics
+	default:
+		return NoGraphics
+	}
+}
+
+func (t *terminal) enter(kind nodeKind, name string) error {
+	switch kind {
+	case sectionNode:
+		underline := "~"
+		switch t.essay.Depth() {
+		case 1:
+			underline = "="
+		case 2:
+			underline = "-"
+		}
+		return t.lines("", name, strings.Repeat(underline, utf8.RuneCountInString(name)), "")
+	case calloutNode:
+		if err := t.lines("┃ " + CalloutKind(name).Title()); err != nil {
+			return err
+		}
+		t.prefix = append(t.prefix, "┃ ")
+	case tabNode:
+		return t.lines("▸ " + name)
+	case displayNode:
+		if name != "" {
+			return t.lines("<" + name + ">")
+		}
+	}
+	return nil
+}
+
+func (t *terminal) leave(kind nodeKind) error {
+	if kind == calloutNode {
+		t.prefix = t.prefix[:len(t.prefix)-1]
+		return t.lines("")
+	}
+	return nil
+}
+
+func (t *terminal) text(s string) error {
+	return t.paragraph(flowText(s))
+}
+
+func (t *terminal) html(h template.HTML) error {
+	return t.paragraph(flowText(htmlTag.ReplaceAllString(string(h), " ")))
+}
+
+func (t *terminal) paragraph(s string) error {
+	if s == "" {
+		return nil
+	}
+	return t.lines(append(wrap(s, t.width-t.indent()), "")...)
+}
+
+func (t *terminal) indent() int {
+	return utf8.RuneCountInString(strings.Join(t.prefix, ""))
+}
+
+func (t *terminal) lines(lines ...string) error {
+	prefix := strings.Join(t.prefix, "")
+	for _, line := range lines {
+		if _, err := io.WriteString(t.essay.out, strings.TrimRight(prefix+line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *terminal) Theme() Theme {
+	return t.essay.theme
+}
+
+func (t *terminal) PixelRatio() float64 {
+	return t.essay.PixelRatio()
+}
+
+func (t *terminal) RenderImage(img EncodedImage) (interface{}, error) {
+	defer recovery.Here()()
+	var err error
+	switch {
+	case t.graphics == KittyGraphics && img.Kind == PNG:
+		err = writeKitty(t.essay.out, img.Data)
+	case t.graphics == SixelGraphics && img.Kind != SVG:
+		var decoded image.Image
+		if decoded, _, err = image.Decode(bytes.NewReader(img.Data)); err == nil {
+			err = writeSixel(t.essay.out, decoded)
+		}
+	default:
+		placeholder := fmt.Sprintf("[%s image %dx%d]", img.Kind, img.Bounds.Dx(), img.Bounds.Dy())
+		if img.Alt != "" {
+			placeholder = "[" + img.Alt + "]"
+		}
+		if err := t.lines(placeholder, ""); err != nil {
+			return nil, err
+		}
+		return nil, t.paragraph(img.Description)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := t.lines("", ""); err != nil {
+		return nil, err
+	}
+	return nil, t.paragraph(img.Description)
+}
+
+func (t *terminal) RenderTable(tab Table) (interface{}, error) {
+	defer recovery.Here()()
+	var rows [][][]string
+	cell := func(item interface{}) ([]string, error) {
+		var buf bytes.Buffer
+		inner := &terminal{
+			essay:    t.essay,
+			width:    tableCellWidth,
+			graphics: NoGraphics,
+		}
+		if err := t.essay.capture(&buf, func() error {
+			return t.essay.visit(inner, item)
+		}); err != nil {
+			return nil, err
+		}
+		return strings.Split(strings.TrimSpace(buf.String()), "\n"), nil
+	}
+	row := func(left interface{}, items []interface{}) error {
+		var r [][]string
+		if tab.LeftCol != nil {
+			c, err := cell(left)
+			if err != nil {
+				return err
+			}
+			r = append(r, c)
+		}
+		for _, item := range items {
+			c, err := cell(item)
+			if err != nil {
+				return err
+			}
+			r = append(r, c)
+		}
+		rows = append(rows, r)
+		return nil
+	}
+	if tab.TopRow != nil {
+		var left interface{} = ""
+		if err := row(left, tab.TopRow); err != nil {
+			return nil, err
+		}
+	}
+	for i, items := range tab.Cells {
+		var left interface{}
+		if i < len(tab.LeftCol) {
+			left = tab.LeftCol[i]
+		}
+		if err := row(left, items); err != nil {
+			return nil, err
+		}
+	}
+	return nil, t.lines(append(boxTable(rows, tab.TopRow != nil), "")...)
+}
+
+// boxTable draws rows of multi-line cells with box-drawing
+// characters.
+func boxTable(rows [][][]string, header bool) []string {
+	var widths []int
+	for _, r := range rows {
+		for i, c := range r {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			for _, line := range c {
+				widths[i] = max(widths[i], utf8.RuneCountInString(line))
+			}
+		}
+	}
+	rule := func(left, mid, right string) string {
+		var parts []string
+		for _, w := range widths {
+			parts = append(parts, strings.Repeat("─", w+2))
+		}
+		return left + strings.Join(parts, mid) + right
+	}
+
+	out := []string{rule("┌", "┬", "┐")}
+	for ri, r := range rows {
+		height := 1
+		for _, c := range r {
+			height = max(height, len(c))
+		}
+		for l := 0; l < height; l++ {
+			line := "│"
+			for i, w := range widths {
+				text := ""
+				if i < len(r) && l < len(r[i]) {
+					text = r[i][l]
+				}
+				line += " " + text + strings.Repeat(" ", w-utf8.RuneCountInString(text)) + " │"
+			}
+			out = append(out, line)
+		}
+		if ri == 0 && header && len(rows) > 1 {
+			out = append(out, rule("├", "┼", "┤"))
+		}
+	}
+	return append(out, rule("└", "┴", "┘"))
+}
+
+// wrap breaks text into lines of at most width runes, except for
+// words that are longer.
+func wrap(s string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// writeKitty displays a PNG using the Kitty graphics protocol.
+func writeKitty(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for first := true; first || encoded != ""; first = false {
+		chunk := encoded
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		encoded = encoded[len(chunk):]
+
+		more := 0
+		if encoded != "" {
+			more = 1
+		}
+		control := fmt.Sprintf("m=%d", more)
+		if first {
+			control = "a=T,f=100," + control
+		}
+		if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", control, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSixel displays an image as DEC sixel graphics, dithered to
+// a 256 color palette.
+func writeSixel(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\x1bPq\"1;1;%d;%d", bounds.Dx(), bounds.Dy())
+	for i, c := range paletted.Palette {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	for y0 := bounds.Min.Y; y0 < bounds.Max.Y; y0 += 6 {
+		used := map[uint8]bool{}
+		for y := y0; y < y0+6 && y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				used[paletted.ColorIndexAt(x, y)] = true
+			}
+		}
+		for idx := range paletted.Palette {
+			if !used[uint8(idx)] {
+				continue
+			}
+			fmt.Fprintf(&buf, "#%d", idx)
+			var run int
+			var last byte
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(&buf, "!%d%c", run, last)
+				case run > 0:
+					buf.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && y0+dy < bounds.Max.Y; dy++ {
+					if paletted.ColorIndexAt(x, y0+dy) == uint8(idx) {
+						bits |= 1 << dy
+					}
+				}
+				sixel := 63 + bits
+				if sixel == last {
+					run++
+					continue
+				}
+				flush()
+				last, run = sixel, 1
+			}
+			flush()
+			buf.WriteByte('$')
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,145 @@
+package essay
+
+import (
+	"fmt"
+	"html/template"
+	"image/color"
+	"strings"
+	"sync"
+)
+
+const (
+	LightTheme = "light"
+	DarkTheme  = "dark"
+	PrintTheme = "print"
+)
+
+type (
+	// Theme styles the page and the plots drawn on it, so that
+	// they match.  The num builders use its colors, font and
+	// line width wherever they are not set explicitly.
+	Theme struct {
+		Name string
+
+		Background color.Color
+		Foreground color.Color
+
+		// Muted colors secondary text and rules, Accent
+		// colors links.
+		Muted  color.Color
+		Accent color.Color
+
+		// FontFamily is the CSS font of the page.  PlotFont is
+		// the Liberation variant of plot text: Serif, Sans or
+		// Mono.  FontSize, in points, applies to both.
+		FontFamily string
+		PlotFont   string
+		FontSize   float64
+
+		// PageWidth is the widest the page grows, in CSS
+		// pixels.
+		PageWidth int
+
+		// LineWidth is the width of plot lines, in points.
+		LineWidth float64
+	}
+)
+
+var (
+	themesLock sync.Mutex
+	themes     = map[string]Theme{
+		LightTheme: {
+			Name:       LightTheme,
+			Background: color.White,
+			Foreground: color.Black,
+			Muted:      color.RGBA{R: 0x66, G: 0x66, B: 0x66, A: 0xff},
+			Accent:     color.RGBA{R: 0x1f, G: 0x5f, B: 0xbf, A: 0xff},
+			FontFamily: "Georgia, serif",
+			PlotFont:   "Serif",
+			FontSize:   12,
+			PageWidth:  960,
+			LineWidth:  1,
+		},
+		DarkTheme: {
+			Name:       DarkTheme,
+			Background: color.RGBA{R: 0x1e, G: 0x1e, B: 0x1e, A: 0xff},
+			Foreground: color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff},
+			Muted:      color.RGBA{R: 0x9a, G: 0x9a, B: 0x9a, A: 0xff},
+			Accent:     color.RGBA{R: 0x6e, G: 0xa8, B: 0xfe, A: 0xff},
+			FontFamily: "Helvetica, Arial, sans-serif",
+			PlotFont:   "Sans",
+			FontSize:   12,
+			PageWidth:  960,
+			LineWidth:  1.5,
+		},
+		PrintTheme: {
+			Name:       PrintTheme,
+			Background: color.White,
+			Foreground: color.Black,
+			Muted:      color.RGBA{R: 0x44, G: 0x44, B: 0x44, A: 0xff},
+			Accent:     color.Black,
+			FontFamily: `"Times New Roman", Times, serif`,
+			PlotFont:   "Serif",
+			FontSize:   11,
+			PageWidth:  720,
+			LineWidth:  0.75,
+		},
+	}
+)
+
+// RegisterTheme adds a theme, or replaces the theme of the same
+// name, for selection by Config.Theme.
+func RegisterTheme(t Theme) {
+	themesLock.Lock()
+	defer themesLock.Unlock()
+	themes[t.Name] = t
+}
+
+// LookupTheme returns the registered theme, by default LightTheme.
+func LookupTheme(name string) (Theme, error) {
+	if name == "" {
+		name = LightTheme
+	}
+	themesLock.Lock()
+	defer themesLock.Unlock()
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme: %q", name)
+	}
+	return t, nil
+}
+
+// Theme returns the theme of the essay.
+func (e *Essay) Theme() Theme {
+	return e.theme
+}
+
+// cssVariables declares the theme as CSS custom properties of
+// :root, for the stylesheets.  The stylesheets are templates in
+// HTML text context, where CSS would be escaped, so the block is
+// returned as HTML.
+func (t Theme) cssVariables() template.HTML {
+	vars := []string{
+		"--background: " + cssColor(t.Background),
+		"--foreground: " + cssColor(t.Foreground),
+		"--muted: " + cssColor(t.Muted),
+		"--accent: " + cssColor(t.Accent),
+		"--font-family: " + t.FontFamily,
+		fmt.Sprintf("--font-size: %gpt", t.FontSize),
+		fmt.Sprintf("--page-width: %dpx", t.PageWidth),
+	}
+	return template.HTML(":root {\n    " + strings.Join(vars, ";\n    ") + ";\n}")
+}
+
+func cssColor(c color.Color) string {
+	r, g, b, a := c.RGBA()
+	if a == 0xffff {
+		return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+	if a == 0 {
+		return "transparent"
+	}
+	// Undo the premultiplied alpha.
+	r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+	return fmt.Sprintf("rgba(%d, %d, %d, %.3g)", r>>8, g>>8, b>>8, float64(a)/0xffff)
+}
@@ -0,0 +1,21 @@
+package essay
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThemeStylesheet(t *testing.T) {
+	tmpl := template.Must(template.ParseFiles("tmpl/style.css"))
+	theme, err := LookupTheme(PrintTheme)
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	require.NoError(t, tmpl.Execute(&buf, theme.cssVariables()))
+	require.Contains(t, buf.String(), `--font-family: "Times New Roman", Times, serif;`)
+	require.NotContains(t, buf.String(), "&#34;")
+	require.True(t, strings.HasPrefix(buf.String(), ":root {\n"))
+}
@@ -0,0 +1,158 @@
+package essay
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/jmacd/essay/internal/recovery"
+)
+
+const (
+	sectionNode nodeKind = iota
+	noteNode
+	calloutNode
+	tabsNode
+	tabNode
+	displayNode
+)
+
+type (
+	nodeKind int
+
+	// visitor receives the document from walk, for backends
+	// that are not written with the HTML templates.  Renderers
+	// in the document render themselves through the visitor's
+	// Builtin methods.
+	visitor interface {
+		Builtin
+
+		// enter begins a node, named by its heading, callout
+		// kind, tab name or displayer type.
+		enter(kind nodeKind, name string) error
+		leave(kind nodeKind) error
+
+		text(string) error
+		html(template.HTML) error
+	}
+)
+
+var nodeKinds = [...]string{
+	sectionNode: "section",
+	noteNode:    "note",
+	calloutNode: "callout",
+	tabsNode:    "tabs",
+	tabNode:     "tab",
+	displayNode: "display",
+}
+
+func (k nodeKind) String() string {
+	return nodeKinds[k]
+}
+
+// walk visits the document in order, expanding Displayers and
+// waiting for Slots, while keeping the depth, section path,
+// profile, TODO and parameter bookkeeping of the HTML templates.
+func (e *Essay) walk(v visitor, divs []interface{}) error {
+	for _, div := range divs {
+		if err := e.visit(v, div); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Essay) walkNode(v visitor, kind nodeKind, name string, divs []interface{}) error {
+	if err := v.enter(kind, name); err != nil {
+		return err
+	}
+	if err := e.walk(v, divs); err != nil {
+		return err
+	}
+	return v.leave(kind)
+}
+
+func (e *Essay) visit(v visitor, item interface{}) (err error) {
+	defer recovery.Here()()
+	switch t := item.(type) {
+	case string:
+		return v.text(t)
+	case template.HTML:
+		return v.html(t)
+	case *sectionRenderer:
+		var out interface{}
+		defer e.push(t.name).pop()
+		defer e.descend().ascend()
+		if !e.selected() {
+			return e.walkNode(v, sectionNode, t.name, skippedBody)
+		}
+		defer e.profile(sectionEntry, t.name)(&out)
+		return e.walkNode(v, sectionNode, t.name, t.contents())
+	case *noteRenderer:
+		return e.walkNode(v, noteNode, "", t.contents())
+	case *calloutRenderer:
+		t.recordTodo()
+		return e.walkNode(v, calloutNode, string(t.kind), t.contents())
+	case *tabsRenderer:
+		if err := v.enter(tabsNode, ""); err != nil {
+			return err
+		}
+		for _, tab := range t.tabs {
+			if err := e.visit(v, tab); err != nil {
+				return err
+			}
+		}
+		return v.leave(tabsNode)
+	case *tabRenderer:
+		defer e.push(t.name).pop()
+		if !e.selected() {
+			return e.walkNode(v, tabNode, t.name, skippedBody)
+		}
+		return e.walkNode(v, tabNode, t.name, t.contents())
+	case *slotRenderer:
+		<-t.done
+		return e.walk(v, t.contents())
+	case *displayRenderer:
+		return e.walkNode(v, displayNode, t.dtype, t.contents())
+	case paramRenderer:
+		_, err := t.Render(v)
+		return err
+	case Renderer:
+		var out interface{}
+		defer e.profile(rendererEntry, simplifyType(t))(&out)
+		if out, err = t.Render(v); err != nil {
+			return err
+		}
+		return e.visitOutput(v, out)
+	case Displayer:
+		var out interface{}
+		defer e.descend().ascend()
+		dtype := displayerType(t)
+		defer e.profile(displayerEntry, dtype)(&out)
+		return e.visit(v, e.display(dtype, t))
+	case func(Document):
+		return e.visit(v, e.display("", funcDisplayer{t}))
+	default:
+		return v.text(fmt.Sprintf("%v", item))
+	}
+}
+
+// visitOutput passes along the result of a Renderer that returned
+// its output instead of writing it through the Builtin.
+func (e *Essay) visitOutput(v visitor, out interface{}) error {
+	switch t := out.(type) {
+	case nil:
+		return nil
+	case string:
+		if t == "" {
+			return nil
+		}
+		return v.text(t)
+	case template.HTML:
+		if t == "" {
+			return nil
+		}
+		return v.html(t)
+	default:
+		return v.text(fmt.Sprintf("%v", t))
+	}
+}